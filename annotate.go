@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// function words that aren't annotated even when the dictionary has them
+var stopWords map[string]bool = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "but": true, "if": true,
+	"of": true, "to": true, "in": true, "on": true, "at": true, "by": true, "for": true,
+	"with": true, "from": true, "as": true, "into": true, "than": true, "then": true,
+	"is": true, "are": true, "was": true, "were": true, "be": true, "been": true, "am": true,
+	"do": true, "does": true, "did": true, "have": true, "has": true, "had": true,
+	"i": true, "you": true, "he": true, "she": true, "it": true, "we": true, "they": true,
+	"me": true, "him": true, "her": true, "us": true, "them": true, "my": true, "your": true,
+	"his": true, "its": true, "our": true, "their": true, "this": true, "that": true,
+	"these": true, "those": true, "not": true, "no": true, "so": true, "can": true,
+	"will": true, "would": true, "should": true, "could": true, "may": true, "might": true,
+	"must": true, "shall": true, "there": true, "here": true, "what": true, "which": true,
+	"who": true, "whom": true, "when": true, "where": true, "why": true, "how": true,
+}
+
+// token of an annotated text, the words found in the dictionary have the
+// lemma and the Word, every other token (spaces, punctuation, function words
+// and unknown words) only the text
+type AnnotatedToken struct {
+	Text  string
+	Lemma string
+	Word  *Word
+}
+
+// split the text in words and the runs of characters between them, joining
+// the tokens gives back the text
+func tokenize(text string) []string {
+	var tokens []string = make([]string, 0)
+	var current strings.Builder
+	var inWord bool
+	runes := []rune(text)
+	for i, r := range runes {
+		// apostrophes and hyphens between letters are part of the word
+		isWordRune := unicode.IsLetter(r) || (inWord && (r == '\'' || r == '-') && i+1 < len(runes) && unicode.IsLetter(runes[i+1]))
+		if isWordRune != inWord && current.Len() != 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+		inWord = isWordRune
+		current.WriteRune(r)
+	}
+	if current.Len() != 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+func isWordToken(token string) bool {
+	for _, r := range token {
+		return unicode.IsLetter(r)
+	}
+	return false
+}
+
+// group the tokens in sentences, a sentence ends at the token with the
+// final punctuation
+func splitSentences(tokens []string) [][]string {
+	var sentences [][]string = make([][]string, 0)
+	start := 0
+	for i, token := range tokens {
+		if !isWordToken(token) && strings.ContainsAny(token, ".!?") {
+			sentences = append(sentences, tokens[start:i+1])
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		sentences = append(sentences, tokens[start:])
+	}
+	return sentences
+}
+
+// count the senses of every lexical entry of the word
+func countSenses(word *Word) int {
+	count := 0
+	for _, wordDefinition := range word.WordDefinitions {
+		count += len(wordDefinition.Definitions)
+	}
+	return count
+}
+
+// tokenize the text and look up every content word by its lemma, the senses
+// of the words with more than one are ranked by the sentence around them
+func annotateText(dict *OpenEnglishDictionary, text string) []AnnotatedToken {
+	var annotated []AnnotatedToken = make([]AnnotatedToken, 0)
+	// the plain search of a word is done only once, the words with more than
+	// one sense are still ranked again in every sentence where they appear
+	var cache map[string]*Word = make(map[string]*Word)
+	for _, sentence := range splitSentences(tokenize(text)) {
+		context := strings.Join(sentence, "")
+		for _, token := range sentence {
+			annotatedToken := AnnotatedToken{Text: token}
+			// the possessive isn't part of the word, "mouse's" is "mouse"
+			base := strings.TrimSuffix(token, "'s")
+			if isWordToken(token) && !stopWords[strings.ToLower(base)] {
+				if lemma, ok := dict.Lemmatize(base); ok {
+					word, cached := cache[lemma]
+					if !cached {
+						word, _ = dict.Search(lemma)
+						cache[lemma] = word
+					}
+					if word != nil && countSenses(word) > 1 {
+						word, _ = dict.SearchInContext(lemma, context)
+					}
+					if word != nil {
+						annotatedToken.Lemma = lemma
+						annotatedToken.Word = word
+					}
+				}
+			}
+			annotated = append(annotated, annotatedToken)
+		}
+	}
+	return annotated
+}
+
+// text of the tooltip of an annotated word, the first definition of each
+// part of speech of the lemma, the most relevant in the sentence
+func tooltipText(token AnnotatedToken) string {
+	var lines []string = make([]string, 0, len(token.Word.WordDefinitions))
+	for _, wordDefinition := range token.Word.WordDefinitions {
+		if len(wordDefinition.Definitions) == 0 || len(wordDefinition.Definitions[0].Definitions) == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s (%s): %s", wordDefinition.WrittenForm, wordDefinition.PartOfSpeech, wordDefinition.Definitions[0].Definitions[0]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+var annotatedHTMLTemplate *template.Template = template.Must(template.New("annotated").Funcs(template.FuncMap{
+	"tooltip": tooltipText,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: serif; max-width: 45em; margin: 2em auto; line-height: 1.6; white-space: pre-wrap; }
+.w { border-bottom: 1px dotted #888; cursor: help; }
+</style>
+</head>
+<body>
+{{- range .Tokens}}{{if .Word}}<span class="w" title="{{tooltip .}}">{{.Text}}</span>{{else}}{{.Text}}{{end}}{{end -}}
+</body>
+</html>
+`))
+
+// write the text as an HTML document where the annotated words show their
+// definitions when hovered
+func writeAnnotatedHTML(w io.Writer, title string, tokens []AnnotatedToken) error {
+	return annotatedHTMLTemplate.Execute(w, struct {
+		Title  string
+		Tokens []AnnotatedToken
+	}{Title: title, Tokens: tokens})
+}
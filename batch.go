@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// number of corrections suggested for each word not found
+const batchCorrectionsLimit = 5
+
+// formats of the glossary written by writeGlossary
+var glossaryFormats []string = []string{"json", "csv", "markdown"}
+
+// result of the lookup of one term of the batch
+type BatchEntry struct {
+	Term string `json:"term"`
+	Word *Word  `json:"word,omitempty"`
+	// only for the terms not found
+	Suggestions []string `json:"suggestions,omitempty"`
+	Err         error    `json:"-"`
+}
+
+// read the terms of the batch, one per line for the "lines" format or from
+// the column (starting at 1) of a "csv" or "tsv" file, the blank terms and
+// the repeated ones are skipped
+func readBatchTerms(r io.Reader, format string, column int, header bool) ([]string, error) {
+	var terms []string = make([]string, 0)
+	var seen map[string]bool = make(map[string]bool)
+	add := func(term string) {
+		term = strings.TrimSpace(term)
+		if term != "" && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	switch format {
+	case "lines":
+		scanner := bufio.NewScanner(r)
+		for first := true; scanner.Scan(); first = false {
+			if first && header {
+				continue
+			}
+			add(scanner.Text())
+		}
+		return terms, scanner.Err()
+	case "csv", "tsv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		if format == "tsv" {
+			reader.Comma = '\t'
+			reader.LazyQuotes = true
+		}
+		for first := true; ; first = false {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return terms, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if (first && header) || column > len(record) {
+				continue
+			}
+			add(record[column-1])
+		}
+	}
+	return nil, errors.New("Invalid input format, use lines, csv or tsv!")
+}
+
+// search the terms with the workers sharing the dictionary, the entries are
+// returned in the order of the terms
+func lookupBatch(dict *OpenEnglishDictionary, terms []string, workers int) []BatchEntry {
+	var entries []BatchEntry = make([]BatchEntry, len(terms))
+	var indexes chan int = make(chan int)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				entries[i] = lookupBatchTerm(dict, terms[i])
+			}
+		}()
+	}
+	for i := range terms {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return entries
+}
+
+// the terms are searched as written and then in lower case
+func lookupBatchTerm(dict *OpenEnglishDictionary, term string) BatchEntry {
+	word, err := dict.Search(term)
+	if err != nil {
+		word, err = dict.Search(strings.ToLower(term))
+	}
+	if err != nil {
+		return BatchEntry{Term: term, Err: err, Suggestions: dict.Corrections(term, batchCorrectionsLimit)}
+	}
+	return BatchEntry{Term: term, Word: word}
+}
+
+// write the glossary of the terms found in the format: json, csv or markdown
+func writeGlossary(w io.Writer, entries []BatchEntry, format string) error {
+	var found []BatchEntry = make([]BatchEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Err == nil {
+			found = append(found, entry)
+		}
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(found)
+	case "csv":
+		// one row per sense
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"term", "lemma", "part_of_speech", "sense", "definition", "examples"})
+		for _, entry := range found {
+			for _, wordDefinition := range entry.Word.WordDefinitions {
+				for i, def := range wordDefinition.Definitions {
+					examples := make([]string, len(def.UseExamples))
+					for j, example := range def.UseExamples {
+						examples[j] = example.Text
+					}
+					writer.Write([]string{
+						entry.Term,
+						wordDefinition.WrittenForm,
+						wordDefinition.PartOfSpeech.String(),
+						fmt.Sprint(i + 1),
+						strings.Join(def.Definitions, "; "),
+						strings.Join(examples, " | "),
+					})
+				}
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case "markdown":
+		builder := &strings.Builder{}
+		builder.WriteString("# Glossary\n")
+		for _, entry := range found {
+			builder.WriteString(fmt.Sprintf("\n## %s\n\n%s", entry.Term, markdownText(entry.Word)))
+		}
+		_, err := io.WriteString(w, builder.String())
+		return err
+	}
+	return ErrInvalidFormat
+}
+
+// write a line for each term not found with the suggested corrections
+func writeBatchReport(w io.Writer, entries []BatchEntry) error {
+	for _, entry := range entries {
+		if entry.Err == nil {
+			continue
+		}
+		line := fmt.Sprintf("%s: %s", entry.Term, entry.Err)
+		if len(entry.Suggestions) != 0 {
+			line += fmt.Sprintf(" Did you mean: %s?", strings.Join(entry.Suggestions, ", "))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
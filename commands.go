@@ -0,0 +1,387 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// word-def similar [-metric path|wup|lch] word1 word2
+func runSimilarCommand(dict *OpenEnglishDictionary, args []string) int {
+	flags := flag.NewFlagSet("similar", flag.ContinueOnError)
+	metricName := flags.String("metric", "", "similarity metric: path, wup or lch (all of them by default)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: word-def similar [-metric path|wup|lch] word1 word2")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	metrics := []SimilarityMetric{SimilarityMetricPath, SimilarityMetricWuPalmer, SimilarityMetricLeacockChodorow}
+	if *metricName != "" {
+		metric, err := ParseSimilarityMetric(*metricName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		metrics = []SimilarityMetric{metric}
+	}
+
+	status := 0
+	for _, metric := range metrics {
+		similarity, err := dict.Similarity(flags.Arg(0), flags.Arg(1), metric)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", metric, err)
+			status = 1
+			continue
+		}
+		fmt.Printf("%s: %.4f\n", metric, similarity)
+	}
+	return status
+}
+
+// word-def serve [-addr host:port]
+func runServeCommand(dict *OpenEnglishDictionary, args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Serving the dictionary on http://%s\n", *addr)
+	if err := serveAPI(ctx, *addr, dict); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// word-def dictd [-addr host:port]
+func runDictdCommand(dict *OpenEnglishDictionary, args []string) int {
+	flags := flag.NewFlagSet("dictd", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:2628", "address to listen on")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Serving the DICT protocol on %s\n", *addr)
+	if err := serveDict(ctx, *addr, dict); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// word-def lsp, speaks the language server protocol over stdin and stdout
+func runLSPCommand(dict *OpenEnglishDictionary, args []string) int {
+	flags := flag.NewFlagSet("lsp", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	return newLSPServer(dict, os.Stdin, os.Stdout).run()
+}
+
+// word-def define [-output format] [-context sentence] [-pos list] word...
+func runDefineCommand(dict *OpenEnglishDictionary, config *Config, args []string) int {
+	flags := flag.NewFlagSet("define", flag.ContinueOnError)
+	output := flags.String("output", config.Output, "output format: "+strings.Join(RendererFormats(), ", "))
+	sentence := flags.String("context", "", "sentence where the words appear, the senses are ranked by it")
+	pos := flags.String("pos", "", "only these parts of speech, like v or n,v (also with the word:pos syntax)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: word-def define [-output format] [-context sentence] [-pos list] word...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	renderer, err := NewRenderer(*output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	var partsOfSpeech []PartOfSpeech
+	if *pos != "" {
+		if partsOfSpeech, err = ParsePartsOfSpeech(*pos); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	status := 0
+	for _, query := range flags.Args() {
+		query = withPartOfSpeechFilter(query, partsOfSpeech)
+		var word *Word
+		if *sentence != "" {
+			word, err = dict.SearchInContext(query, *sentence)
+		} else {
+			word, err = dict.Search(query)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", query, err)
+			status = 1
+			continue
+		}
+		if err := renderer.Render(os.Stdout, word); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return status
+}
+
+// word-def batch [flags] [file], the terms are read from stdin without file
+func runBatchCommand(dict *OpenEnglishDictionary, config *Config, args []string) int {
+	flags := flag.NewFlagSet("batch", flag.ContinueOnError)
+	inputFormat := flags.String("input", "", "input format: lines, csv or tsv (by the file extension by default)")
+	column := flags.Int("column", 1, "column of the terms in csv and tsv files, starting at 1")
+	header := flags.Bool("header", false, "skip the first line of the input")
+	output := flags.String("output", config.GlossaryOutput, "glossary format: "+strings.Join(glossaryFormats, ", "))
+	outputFile := flags.String("o", "", "write the glossary to the file instead of stdout")
+	reportFile := flags.String("report", "", "write the words not found to the file instead of stderr")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of concurrent lookups")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: word-def batch [flags] [file]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 1 || *column < 1 {
+		flags.Usage()
+		return 2
+	}
+	// checked before the lookup and before the output file is truncated
+	if !slices.Contains(glossaryFormats, *output) {
+		fmt.Fprintln(os.Stderr, ErrInvalidFormat)
+		return 2
+	}
+
+	var input io.Reader = os.Stdin
+	if flags.NArg() == 1 && flags.Arg(0) != "-" {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		input = file
+		if extension := strings.ToLower(filepath.Ext(flags.Arg(0))); *inputFormat == "" && (extension == ".csv" || extension == ".tsv") {
+			*inputFormat = extension[1:]
+		}
+	}
+	if *inputFormat == "" {
+		*inputFormat = "lines"
+	}
+
+	terms, err := readBatchTerms(input, *inputFormat, *column, *header)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	entries := lookupBatch(dict, terms, *workers)
+
+	var glossary io.Writer = os.Stdout
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		glossary = file
+	}
+	if err := writeGlossary(glossary, entries, *output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var report io.Writer = os.Stderr
+	if *reportFile != "" {
+		file, err := os.Create(*reportFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		report = file
+	}
+	if err := writeBatchReport(report, entries); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// word-def annotate [-o file.html] [file], the text is read from stdin
+// without file
+func runAnnotateCommand(dict *OpenEnglishDictionary, args []string) int {
+	flags := flag.NewFlagSet("annotate", flag.ContinueOnError)
+	outputFile := flags.String("o", "", "write the HTML to the file instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: word-def annotate [-o file.html] [file]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	var input io.Reader = os.Stdin
+	title := "word-def"
+	if flags.NArg() == 1 && flags.Arg(0) != "-" {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		input = file
+		title = filepath.Base(flags.Arg(0))
+	}
+	text, err := io.ReadAll(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var output io.Writer = os.Stdout
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		output = file
+	}
+	if err := writeAnnotatedHTML(output, title, annotateText(dict, string(text))); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// word-def lists [show|add|remove|delete|export] ..., without arguments the
+// lists are printed with the number of words
+func runListsCommand(dict *OpenEnglishDictionary, args []string) int {
+	usage := func() int {
+		fmt.Fprintln(os.Stderr, `Usage:
+  word-def lists
+  word-def lists show list
+  word-def lists add list word...
+  word-def lists remove list word...
+  word-def lists delete list
+  word-def lists export [-output csv|markdown|anki|json] [-o file] list`)
+		return 2
+	}
+	lists, err := LoadWordLists(wordListsPath())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(args) == 0 {
+		for _, list := range lists.Lists {
+			fmt.Printf("%s (%d)\n", list.Name, len(list.Words))
+		}
+		return 0
+	}
+
+	switch args[0] {
+	case "show":
+		if len(args) != 2 {
+			return usage()
+		}
+		list, err := lists.Find(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", args[1], err)
+			return 1
+		}
+		for _, word := range list.Words {
+			fmt.Println(word)
+		}
+	case "add", "remove":
+		if len(args) < 3 {
+			return usage()
+		}
+		status := 0
+		for _, word := range args[2:] {
+			var err error
+			if args[0] == "add" {
+				err = lists.Add(args[1], word)
+			} else {
+				err = lists.Remove(args[1], word)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", word, err)
+				status = 1
+			}
+		}
+		return status
+	case "delete":
+		if len(args) != 2 {
+			return usage()
+		}
+		if err := lists.Delete(args[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", args[1], err)
+			return 1
+		}
+	case "export":
+		flags := flag.NewFlagSet("lists export", flag.ContinueOnError)
+		output := flags.String("output", "csv", "export format: csv, markdown, anki or json")
+		outputFile := flags.String("o", "", "write the export to the file instead of stdout")
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if flags.NArg() != 1 {
+			return usage()
+		}
+		list, err := lists.Find(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", flags.Arg(0), err)
+			return 1
+		}
+		var w io.Writer = os.Stdout
+		if *outputFile != "" {
+			file, err := os.Create(*outputFile)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			defer file.Close()
+			w = file
+		}
+		if err := exportWordList(w, dict, list, *output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	default:
+		return usage()
+	}
+	return 0
+}
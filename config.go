@@ -0,0 +1,283 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gdamore/tcell/v2"
+)
+
+// settings read from the config.toml file of the config directory, the
+// missing ones keep the default values
+type Config struct {
+	// path of the WN-LMF file, relative paths are resolved from the working
+	// directory
+	Dictionary string
+	// default output format of the define command and of the batch glossary
+	Output         string
+	GlossaryOutput string
+	// initial display mode of the TUI
+	Verbose     bool
+	ShowRelated bool
+	ThemeName   string
+	Theme       Theme
+	Keys        KeyBindings
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Dictionary:     "wn.xml",
+		Output:         "plain",
+		GlossaryOutput: "markdown",
+		Verbose:        true,
+		ShowRelated:    false,
+		ThemeName:      "default",
+		Theme:          themes["default"],
+		Keys:           defaultKeyBindings(),
+	}
+}
+
+// the config directory keeps the settings of the user
+func configDir() string {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+func configPath() string {
+	return filepath.Join(configDir(), "config.toml")
+}
+
+// load the config file, a missing or unreadable file gives the default
+// config and the invalid values keep their defaults, the unknown keys are
+// returned as warnings, the no-color theme replaces the configured one when
+// NO_COLOR is set
+func LoadConfig(path string) (*Config, []string, error) {
+	config, warnings, err := readConfig(path)
+	if noColor() {
+		config.ThemeName = "no-color"
+		config.Theme = themes["no-color"]
+	}
+	return config, warnings, err
+}
+
+func readConfig(path string) (*Config, []string, error) {
+	config := NewConfig()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil, nil
+	}
+	if err != nil {
+		return config, nil, err
+	}
+	var document map[string]any
+	if _, err := toml.Decode(string(data), &document); err != nil {
+		return config, nil, err
+	}
+	warnings, err := config.apply(flattenTOML("", document, make(map[string]any)))
+	return config, warnings, err
+}
+
+// flatten the tables of the document, the keys of the result are prefixed by
+// the tables, like "theme.headword"
+func flattenTOML(prefix string, table map[string]any, values map[string]any) map[string]any {
+	for key, value := range table {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if subtable, ok := value.(map[string]any); ok {
+			flattenTOML(key, subtable, values)
+		} else {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// set the config from the values of the file, keyed by "section.key", the
+// theme name is applied before the styles that override it, the invalid
+// values are skipped and returned together as the error and the unknown
+// keys as warnings
+func (c *Config) apply(values map[string]any) ([]string, error) {
+	var warnings []string = make([]string, 0)
+	var errs []error = make([]error, 0)
+	if name, ok := values["theme.name"]; ok {
+		if theme, ok := themes[fmt.Sprint(name)]; ok {
+			c.ThemeName = fmt.Sprint(name)
+			c.Theme = theme
+		} else {
+			errs = append(errs, ErrInvalidTheme)
+		}
+	}
+	var styles map[string]*string = map[string]*string{
+		"headword":       &c.Theme.Headword,
+		"part_of_speech": &c.Theme.PartOfSpeech,
+		"pronunciation":  &c.Theme.Pronunciation,
+		"word":           &c.Theme.Word,
+		"heading":        &c.Theme.Heading,
+		"definition":     &c.Theme.Definition,
+		"example":        &c.Theme.Example,
+		"sense_example":  &c.Theme.SenseExample,
+		"frame":          &c.Theme.Frame,
+		"relation":       &c.Theme.Relation,
+		"note":           &c.Theme.Note,
+		"success":        &c.Theme.Success,
+	}
+
+	// in the order of the keys, so the messages don't change between runs
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		value := values[key]
+		section, name, _ := strings.Cut(key, ".")
+		var err error
+		switch {
+		case key == "dictionary":
+			err = setConfigValue(&c.Dictionary, value, key)
+		case key == "output.format":
+			err = setConfigValue(&c.Output, value, key)
+		case key == "output.glossary":
+			err = setConfigValue(&c.GlossaryOutput, value, key)
+		case key == "output.verbose":
+			err = setConfigValue(&c.Verbose, value, key)
+		case key == "output.related":
+			err = setConfigValue(&c.ShowRelated, value, key)
+		case key == "theme.name":
+		case section == "theme" && styles[name] != nil:
+			err = setConfigValue(styles[name], value, key)
+		case key == "theme.monochrome":
+			err = setConfigValue(&c.Theme.Monochrome, value, key)
+		case section == "keys" && c.Keys[name].Name != "":
+			var spec string
+			if err = setConfigValue(&spec, value, key); err == nil {
+				err = c.Keys.Set(name, spec)
+			}
+		default:
+			warnings = append(warnings, fmt.Sprintf("Unknown config key %q!", key))
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return warnings, errors.Join(errs...)
+}
+
+func setConfigValue[T any](target *T, value any, key string) error {
+	typed, ok := value.(T)
+	if !ok {
+		return fmt.Errorf("Invalid value for the config key %q!", key)
+	}
+	*target = typed
+	return nil
+}
+
+// a key with its modifiers, like Ctrl-T or Alt-Left
+type KeyBinding struct {
+	Key  tcell.Key
+	Rune rune
+	Alt  bool
+	// the text of the config, shown in the hints
+	Name string
+}
+
+var keyNames map[string]tcell.Key = map[string]tcell.Key{
+	"left": tcell.KeyLeft, "right": tcell.KeyRight, "up": tcell.KeyUp, "down": tcell.KeyDown,
+	"home": tcell.KeyHome, "end": tcell.KeyEnd, "pgup": tcell.KeyPgUp, "pgdn": tcell.KeyPgDn,
+	"insert": tcell.KeyInsert, "delete": tcell.KeyDelete, "tab": tcell.KeyTab, "backtab": tcell.KeyBacktab,
+	"enter": tcell.KeyEnter, "esc": tcell.KeyEscape,
+	"f1": tcell.KeyF1, "f2": tcell.KeyF2, "f3": tcell.KeyF3, "f4": tcell.KeyF4,
+	"f5": tcell.KeyF5, "f6": tcell.KeyF6, "f7": tcell.KeyF7, "f8": tcell.KeyF8,
+	"f9": tcell.KeyF9, "f10": tcell.KeyF10, "f11": tcell.KeyF11, "f12": tcell.KeyF12,
+}
+
+// parse keys like "Ctrl-T", "Alt-Left", "F2" or a single character, the
+// names aren't case sensitive
+func ParseKeyBinding(spec string) (KeyBinding, error) {
+	binding := KeyBinding{Name: spec}
+	rest := spec
+	if prefix, key, ok := strings.Cut(rest, "-"); ok && strings.EqualFold(prefix, "alt") && key != "" {
+		binding.Alt = true
+		rest = key
+	}
+	if prefix, key, ok := strings.Cut(rest, "-"); ok && strings.EqualFold(prefix, "ctrl") && len(key) == 1 {
+		letter := strings.ToLower(key)[0]
+		if letter < 'a' || letter > 'z' {
+			return binding, fmt.Errorf("Invalid key %q!", spec)
+		}
+		binding.Key = tcell.KeyCtrlA + tcell.Key(letter-'a')
+		return binding, nil
+	}
+	if key, ok := keyNames[strings.ToLower(rest)]; ok {
+		binding.Key = key
+		return binding, nil
+	}
+	if runes := []rune(rest); len(runes) == 1 {
+		binding.Key = tcell.KeyRune
+		binding.Rune = runes[0]
+		// Alt-N is written for alt and the n key, like Ctrl-N
+		if binding.Alt {
+			binding.Rune = unicode.ToLower(binding.Rune)
+		}
+		return binding, nil
+	}
+	return binding, fmt.Errorf("Invalid key %q!", spec)
+}
+
+func (kb KeyBinding) Matches(event *tcell.EventKey) bool {
+	if kb.Alt != (event.Modifiers()&tcell.ModAlt != 0) || event.Key() != kb.Key {
+		return false
+	}
+	return kb.Key != tcell.KeyRune || event.Rune() == kb.Rune
+}
+
+// the keys of the actions of the TUI, by the name used in the [keys] section
+type KeyBindings map[string]KeyBinding
+
+func defaultKeyBindings() KeyBindings {
+	var bindings KeyBindings = make(KeyBindings)
+	for action, spec := range map[string]string{
+		"verbose":    "Ctrl-T",
+		"related":    "Ctrl-R",
+		"part_whole": "Ctrl-P",
+		"compare":    "Ctrl-O",
+		"history":    "Ctrl-Y",
+		"back":       "Alt-Left",
+		"forward":    "Alt-Right",
+		"save":       "Ctrl-S",
+		"lists":      "Ctrl-L",
+		"next_pane":  "Ctrl-N",
+		"next_link":  "Tab",
+		"prev_link":  "Backtab",
+		// toggles of the part of speech filter
+		"filter_noun":      "Alt-N",
+		"filter_verb":      "Alt-V",
+		"filter_adjective": "Alt-A",
+		"filter_adverb":    "Alt-R",
+	} {
+		bindings[action], _ = ParseKeyBinding(spec)
+	}
+	return bindings
+}
+
+// change the key of the action, only the existing actions can be bound
+func (kb KeyBindings) Set(action string, spec string) error {
+	if _, ok := kb[action]; !ok {
+		return fmt.Errorf("Unknown key action %q!", action)
+	}
+	binding, err := ParseKeyBinding(spec)
+	if err != nil {
+		return err
+	}
+	kb[action] = binding
+	return nil
+}
+
+// tell if the event is the key of the action
+func (kb KeyBindings) Is(action string, event *tcell.EventKey) bool {
+	binding, ok := kb[action]
+	return ok && binding.Matches(event)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTestConfig(t *testing.T, text string) string {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	// inline tables, dotted keys and multi-line strings are valid TOML
+	path := writeTestConfig(t, `
+dictionary = """
+/usr/share/wn.xml"""
+output = { format = "json", verbose = false }
+theme.name = "high-contrast"
+
+[keys]
+save = 'Ctrl-K'
+`)
+	config, warnings, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("got warnings %v, want none", warnings)
+	}
+	if config.Dictionary != "/usr/share/wn.xml" || config.Output != "json" || config.Verbose || config.ThemeName != "high-contrast" {
+		t.Errorf("got dictionary %q, output %q, verbose %t and theme %q", config.Dictionary, config.Output, config.Verbose, config.ThemeName)
+	}
+	if config.Keys["save"].Name != "Ctrl-K" {
+		t.Errorf("got the save key %q, want Ctrl-K", config.Keys["save"].Name)
+	}
+}
+
+func TestLoadConfigKeepsTheValidValues(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	path := writeTestConfig(t, `
+dictionary = "/usr/share/wn.xml"
+colour = "blue"
+
+[output]
+format = "json"
+verbose = "no"
+
+[keys]
+teleport = "Ctrl-X"
+`)
+	config, warnings, err := LoadConfig(path)
+	if err == nil {
+		t.Error("got no error for the invalid value of output.verbose")
+	}
+	if !reflect.DeepEqual(warnings, []string{`Unknown config key "colour"!`, `Unknown config key "keys.teleport"!`}) {
+		t.Errorf("got warnings %v", warnings)
+	}
+	if config.Dictionary != "/usr/share/wn.xml" || config.Output != "json" || !config.Verbose {
+		t.Errorf("got dictionary %q, output %q and verbose %t", config.Dictionary, config.Output, config.Verbose)
+	}
+}
+
+func TestLoadConfigInvalidTOML(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	config, _, err := LoadConfig(writeTestConfig(t, "dictionary = \n"))
+	if err == nil {
+		t.Error("got no error for the invalid file")
+	}
+	if !reflect.DeepEqual(config, NewConfig()) {
+		t.Error("got a config different from the default one")
+	}
+}
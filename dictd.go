@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// a client is disconnected after this time without sending a command
+const dictIdleTimeout = 10 * time.Minute
+
+// strategy used by MATCH when the client asks for the server default with "."
+const dictDefaultStrategy = "lev"
+
+// server of the DICT protocol (RFC 2229) exposing the dictionary as a single
+// database named after the lexicon
+type dictServer struct {
+	dict        *OpenEnglishDictionary
+	database    string
+	description string
+	info        string
+	hostname    string
+	// used to build the message id of the banner
+	connections atomic.Int64
+}
+
+func newDictServer(dict *OpenEnglishDictionary) *dictServer {
+	server := &dictServer{
+		dict:        dict,
+		database:    "wn",
+		description: "WordNet",
+		hostname:    "localhost",
+	}
+	if dict.lx != nil && len(dict.lx.Lexicons) != 0 {
+		lexicon := dict.lx.Lexicons[0]
+		server.database = lexicon.Id
+		server.description = lexicon.Label
+		server.info = fmt.Sprintf("%s\nLanguage: %s\nVersion: %s\nLicense: %s\nContact: %s\nHeadwords: %d",
+			lexicon.Label, lexicon.Language, lexicon.Version, lexicon.License, lexicon.Email, len(dict.sortedWords))
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		server.hostname = hostname
+	}
+	return server
+}
+
+// serve the DICT protocol until the context is done, then close the open
+// connections and wait for their handlers
+func serveDict(ctx context.Context, addr string, dict *OpenEnglishDictionary) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	server := newDictServer(dict)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var open map[net.Conn]bool = make(map[net.Conn]bool)
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+		mu.Lock()
+		for conn := range open {
+			conn.Close()
+		}
+		mu.Unlock()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				wg.Wait()
+				return nil
+			}
+			return err
+		}
+		mu.Lock()
+		open[conn] = true
+		mu.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			server.handle(conn)
+			mu.Lock()
+			delete(open, conn)
+			mu.Unlock()
+		}()
+	}
+}
+
+func (ds *dictServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	defer writer.Flush()
+
+	id := ds.connections.Add(1)
+	ds.status(writer, 220, fmt.Sprintf("%s word-def <> <%d.%d@%s>", ds.hostname, os.Getpid(), id, ds.hostname))
+	for {
+		writer.Flush()
+		conn.SetReadDeadline(time.Now().Add(dictIdleTimeout))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		args, err := splitDictCommand(strings.TrimRight(line, "\r\n"))
+		if err != nil {
+			ds.status(writer, 501, "syntax error, illegal parameters")
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		switch strings.ToUpper(args[0]) {
+		case "DEFINE":
+			ds.define(writer, args[1:])
+		case "MATCH":
+			ds.match(writer, args[1:])
+		case "SHOW":
+			ds.show(writer, args[1:])
+		case "CLIENT", "OPTION":
+			ds.status(writer, 250, "ok")
+		case "STATUS":
+			ds.status(writer, 210, "status [d/m/c = 0/0/0; 0.000r 0.000u 0.000s]")
+		case "HELP":
+			ds.status(writer, 113, "help text follows")
+			ds.text(writer, "DEFINE database word         -- look up word in database\n"+
+				"MATCH database strategy word -- match word in database using strategy\n"+
+				"SHOW DB                      -- list all accessible databases\n"+
+				"SHOW STRAT                   -- list available matching strategies\n"+
+				"SHOW INFO database           -- provide information about the database\n"+
+				"SHOW SERVER                  -- provide site-specific information\n"+
+				"QUIT                         -- terminate connection")
+			ds.status(writer, 250, "ok")
+		case "QUIT":
+			ds.status(writer, 221, "bye")
+			return
+		default:
+			ds.status(writer, 500, "unknown command")
+		}
+	}
+}
+
+// the database "*" searches every database and "!" stops at the first one
+// with results, both are the same here since there's only one
+func (ds *dictServer) validDatabase(name string) bool {
+	return name == "*" || name == "!" || strings.EqualFold(name, ds.database)
+}
+
+func (ds *dictServer) define(writer *bufio.Writer, args []string) {
+	if len(args) != 2 {
+		ds.status(writer, 501, "syntax error, illegal parameters")
+		return
+	}
+	if !ds.validDatabase(args[0]) {
+		ds.status(writer, 550, "invalid database, use \"SHOW DB\" for list of databases")
+		return
+	}
+	word, err := ds.dict.Search(args[1])
+	if err != nil {
+		word, err = ds.dict.Search(strings.ToLower(args[1]))
+	}
+	if err != nil || len(word.WordDefinitions) == 0 {
+		ds.status(writer, 552, "no match")
+		return
+	}
+	ds.status(writer, 150, fmt.Sprintf("%d definitions retrieved", len(word.WordDefinitions)))
+	for _, wordDefinition := range word.WordDefinitions {
+		ds.status(writer, 151, fmt.Sprintf("%s %s %s", quoteDictString(wordDefinition.WrittenForm), ds.database, quoteDictString(ds.description)))
+		ds.text(writer, plainDefinitionText(wordDefinition))
+	}
+	ds.status(writer, 250, "ok")
+}
+
+func (ds *dictServer) match(writer *bufio.Writer, args []string) {
+	if len(args) != 3 {
+		ds.status(writer, 501, "syntax error, illegal parameters")
+		return
+	}
+	if !ds.validDatabase(args[0]) {
+		ds.status(writer, 550, "invalid database, use \"SHOW DB\" for list of databases")
+		return
+	}
+	strategy := strings.ToLower(args[1])
+	if strategy == "." {
+		strategy = dictDefaultStrategy
+	}
+	words, err := ds.dict.Match(args[2], strategy)
+	if errors.Is(err, ErrInvalidStrategy) {
+		ds.status(writer, 551, "invalid strategy, use \"SHOW STRAT\" for a list of strategies")
+		return
+	}
+	if err != nil {
+		ds.status(writer, 501, "syntax error, illegal parameters")
+		return
+	}
+	if len(words) == 0 {
+		ds.status(writer, 552, "no match")
+		return
+	}
+	lines := make([]string, len(words))
+	for i, word := range words {
+		lines[i] = fmt.Sprintf("%s %s", ds.database, quoteDictString(word))
+	}
+	ds.status(writer, 152, fmt.Sprintf("%d matches found", len(words)))
+	ds.text(writer, strings.Join(lines, "\n"))
+	ds.status(writer, 250, "ok")
+}
+
+func (ds *dictServer) show(writer *bufio.Writer, args []string) {
+	if len(args) == 0 {
+		ds.status(writer, 501, "syntax error, illegal parameters")
+		return
+	}
+	switch strings.ToUpper(args[0]) {
+	case "DB", "DATABASES":
+		ds.status(writer, 110, "1 databases present")
+		ds.text(writer, fmt.Sprintf("%s %s", ds.database, quoteDictString(ds.description)))
+		ds.status(writer, 250, "ok")
+	case "STRAT", "STRATEGIES":
+		lines := make([]string, len(matchStrategies))
+		for i, strategy := range matchStrategies {
+			lines[i] = fmt.Sprintf("%s %s", strategy.Name, quoteDictString(strategy.Description))
+		}
+		ds.status(writer, 111, fmt.Sprintf("%d strategies available", len(matchStrategies)))
+		ds.text(writer, strings.Join(lines, "\n"))
+		ds.status(writer, 250, "ok")
+	case "INFO":
+		if len(args) != 2 {
+			ds.status(writer, 501, "syntax error, illegal parameters")
+			return
+		}
+		if !ds.validDatabase(args[1]) {
+			ds.status(writer, 550, "invalid database, use \"SHOW DB\" for list of databases")
+			return
+		}
+		ds.status(writer, 112, "database information follows")
+		ds.text(writer, ds.info)
+		ds.status(writer, 250, "ok")
+	case "SERVER":
+		ds.status(writer, 114, "server information follows")
+		ds.text(writer, fmt.Sprintf("word-def on %s", ds.hostname))
+		ds.status(writer, 250, "ok")
+	default:
+		ds.status(writer, 501, "syntax error, illegal parameters")
+	}
+}
+
+func (ds *dictServer) status(writer *bufio.Writer, code int, message string) {
+	fmt.Fprintf(writer, "%d %s\r\n", code, message)
+}
+
+// write a text response ended by a line with a single dot, the lines starting
+// with a dot get another one
+func (ds *dictServer) text(writer *bufio.Writer, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, ".") {
+			line = "." + line
+		}
+		fmt.Fprintf(writer, "%s\r\n", line)
+	}
+	writer.WriteString(".\r\n")
+}
+
+// split the command in words, the words can be quoted with single or double
+// quotes and the backslash escapes the next character
+func splitDictCommand(line string) ([]string, error) {
+	var args []string = make([]string, 0, 4)
+	var current strings.Builder
+	var quote rune
+	var inWord, escaped bool
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New("Unterminated quote!")
+	}
+	if inWord {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+func quoteDictString(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
@@ -1,21 +1,77 @@
 package main
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// returned when the query isn't a lemma or a form of any lexical entry
+var ErrWordNotFound = errors.New("Word not found!")
+
+var ErrSynsetNotFound = errors.New("Synset not found!")
 
 // represent an sense
 type Def struct {
-	Definitions []string
-	UseExamples []string
+	// synset of the sense, to get the synset relations with Synset
+	SynsetId    string   `json:"synset_id"`
+	Definitions []string `json:"definitions"`
+	// interlingual definition, empty when the synset doesn't have one
+	ILIDefinition string `json:"ili_definition,omitempty"`
+	// sense specific examples come first, followed by the synset ones
+	UseExamples []UseExample `json:"examples"`
+	// subcategorization frames of the sense, only verbs have them
+	Frames []string `json:"frames"`
+	// words linked to the sense by a sense relation, grouped by relation type
+	Related []RelatedWords `json:"related"`
+	// verbs entailed or caused by the synset of the sense
+	Entails []string `json:"entails"`
+	Causes  []string `json:"causes"`
+	// the other words of the synset of the sense
+	Synonyms []string `json:"synonyms"`
+}
+
+// tells from which level of the lexicon an example was taken
+type ExampleSource int8
+
+const (
+	ExampleSourceSense = ExampleSource(iota)
+	ExampleSourceSynset
+)
+
+// the source is encoded as "sense" or "synset"
+func (es ExampleSource) MarshalText() ([]byte, error) {
+	if es == ExampleSourceSense {
+		return []byte("sense"), nil
+	}
+	return []byte("synset"), nil
+}
+
+type UseExample struct {
+	Text   string        `json:"text"`
+	Source ExampleSource `json:"source"`
 }
 
 type WordDefinition struct {
-	WrittenForm  string
-	PartOfSpeech string
-	Definitions  []Def
+	WrittenForm  string       `json:"written_form"`
+	PartOfSpeech PartOfSpeech `json:"part_of_speech"`
+	// tags of the lemma itself
+	Tags []Tag `json:"tags"`
+	// pronunciations of the lemma, in the notation of the dictionary
+	Pronunciations []string `json:"pronunciations"`
+	// inflected and variant forms of the lemma, like plurals and past tenses
+	Forms       []WordForm `json:"forms"`
+	Definitions []Def      `json:"definitions"`
+}
+
+type WordForm struct {
+	WrittenForm string `json:"written_form"`
+	Tags        []Tag  `json:"tags"`
 }
 
 type Word struct {
-	WordDefinitions []WordDefinition
+	WordDefinitions []WordDefinition `json:"word_definitions"`
 }
 
 func NewWord() *Word {
@@ -24,8 +80,47 @@ func NewWord() *Word {
 	}
 }
 
+// synset with the relations pointing to the ids of the targets instead of the
+// synsets, so it can be serialized
+type SynsetInfo struct {
+	Id            string               `json:"id"`
+	ILI           string               `json:"ili"`
+	Words         []string             `json:"words"`
+	Definitions   []string             `json:"definitions"`
+	ILIDefinition string               `json:"ili_definition,omitempty"`
+	Examples      []string             `json:"examples"`
+	Relations     []SynsetRelationInfo `json:"relations"`
+}
+
+type SynsetRelationInfo struct {
+	Relation RelationType `json:"relation"`
+	SynsetId string       `json:"synset_id"`
+	Words    []string     `json:"words"`
+}
+
 type Dictionary interface {
+	// search the word grouped by part of speech, "word:pos" searches only the
+	// parts of speech of the comma separated list, like "run:v" or "fast:a,r"
 	Search(query string) (*Word, error)
+	// like Search, but it stops early with the error of the context when the
+	// context is done
+	SearchContext(ctx context.Context, query string) (*Word, error)
+	// return until limit words starting with the prefix in alphabetical
+	// order, without limit if limit <= 0
+	Suggest(prefix string, limit int) []string
+	RelatedWords(query string) ([]RelatedWords, error)
+	Synset(id string) (*SynsetInfo, error)
+	// follow the entails, is_entailed_by, causes and is_caused_by relations of
+	// the verb senses of the query until the depth
+	VerbRelations(query string, depth int) ([]*RelationNode, error)
+	// trees of the parts (meronyms) and the wholes (holonyms) of every sense
+	// of the query until the depth
+	PartsOf(query string, depth int) ([]*RelationNode, error)
+	WholesOf(query string, depth int) ([]*RelationNode, error)
+	// return the lemma of an inflected form, like "mouse" for "mice"
+	Lemmatize(token string) (string, bool)
+	LowestCommonHypernym(a string, b string) (*CommonHypernym, error)
+	ShortestPath(a string, b string) ([]PathStep, error)
 }
 
 type OpenEnglishDictionary struct {
@@ -33,6 +128,17 @@ type OpenEnglishDictionary struct {
 	wordToLexicalEntry map[string][]*LexicalEntry
 	// link the alternatives names for a word inside the Form element and link to the name
 	alternativeNames map[string]string
+	// link every sense to the lexical entry that contains it
+	senseToLexicalEntry map[*Sense]*LexicalEntry
+	// link every synset to the lexical entries that have a sense on it
+	synsetToLexicalEntries map[*Synset][]*LexicalEntry
+	synsetIdToSynset       map[string]*Synset
+	// every lemma sorted, used by the prefix searches
+	sortedWords []string
+	// depth of the synsets in the hypernym hierarchy and the maximum depth of
+	// each part of speech, computed once when the dictionary is created
+	synsetDepth      map[*Synset]int
+	maxTaxonomyDepth map[PartOfSpeech]int
 }
 
 func NewOpenEnglishDictionary(lx *LexicalResource) *OpenEnglishDictionary {
@@ -42,6 +148,9 @@ func NewOpenEnglishDictionary(lx *LexicalResource) *OpenEnglishDictionary {
 
 	var wordToLexicalEntry map[string][]*LexicalEntry = make(map[string][]*LexicalEntry, 100000)
 	var alternativeNames map[string]string = make(map[string]string, 10000)
+	var senseToLexicalEntry map[*Sense]*LexicalEntry = make(map[*Sense]*LexicalEntry, 200000)
+	var synsetToLexicalEntries map[*Synset][]*LexicalEntry = make(map[*Synset][]*LexicalEntry, 150000)
+	var synsetIdToSynset map[string]*Synset = make(map[string]*Synset, 150000)
 
 	for _, lexicalEntry := range lx.Lexicons[0].LexicalEntrys {
 		_, ok := wordToLexicalEntry[lexicalEntry.Lemma.WrittenForm]
@@ -54,50 +163,214 @@ func NewOpenEnglishDictionary(lx *LexicalResource) *OpenEnglishDictionary {
 		for _, form := range lexicalEntry.Forms {
 			alternativeNames[form.WrittenForm] = lexicalEntry.Lemma.WrittenForm
 		}
+		for _, sense := range lexicalEntry.Senses {
+			senseToLexicalEntry[sense] = lexicalEntry
+			synsetToLexicalEntries[sense.Synset] = append(synsetToLexicalEntries[sense.Synset], lexicalEntry)
+		}
+	}
+	for _, synset := range lx.Lexicons[0].Synsets {
+		synsetIdToSynset[synset.Id] = synset
+	}
+	var sortedWords []string = make([]string, 0, len(wordToLexicalEntry))
+	for word := range wordToLexicalEntry {
+		sortedWords = append(sortedWords, word)
 	}
-	return &OpenEnglishDictionary{
-		lx:                 lx,
-		wordToLexicalEntry: wordToLexicalEntry,
-		alternativeNames:   alternativeNames,
+	sort.Strings(sortedWords)
+	oe := &OpenEnglishDictionary{
+		lx:                     lx,
+		wordToLexicalEntry:     wordToLexicalEntry,
+		alternativeNames:       alternativeNames,
+		senseToLexicalEntry:    senseToLexicalEntry,
+		synsetToLexicalEntries: synsetToLexicalEntries,
+		synsetIdToSynset:       synsetIdToSynset,
+		sortedWords:            sortedWords,
 	}
+	oe.computeTaxonomyDepths()
+	return oe
 }
 
-func (oe *OpenEnglishDictionary) Search(query string) (*Word, error) {
+// return the lexical entries of the query, searching by the lemma and then by
+// the alternative names, a query like "run:v" that isn't a word itself
+// returns only the entries of the parts of speech after the colon
+func (oe *OpenEnglishDictionary) findLexicalEntries(query string) ([]*LexicalEntry, error) {
+	finded, err := oe.findWordLexicalEntries(query)
+	if err == nil {
+		return finded, nil
+	}
+	word, partsOfSpeech, ok := splitPartOfSpeechFilter(query)
+	if !ok {
+		return nil, err
+	}
+	finded, err = oe.findWordLexicalEntries(word)
+	if err != nil {
+		return nil, err
+	}
+	finded = filterLexicalEntries(finded, partsOfSpeech)
+	if len(finded) == 0 {
+		return nil, ErrWordNotFound
+	}
+	return finded, nil
+}
+
+func (oe *OpenEnglishDictionary) findWordLexicalEntries(query string) ([]*LexicalEntry, error) {
 	finded, ok := oe.wordToLexicalEntry[query]
 	if !ok {
 		// search by the alternative names
 		altName, okAlt := oe.alternativeNames[query]
 		if !okAlt {
-			return nil, errors.New("Word not found!")
+			return nil, ErrWordNotFound
 		}
         finded, ok = oe.wordToLexicalEntry[altName]
         if !ok {
-			return nil, errors.New("Word not found!")
+			return nil, ErrWordNotFound
         }
 	}
+	return finded, nil
+}
+
+func (oe *OpenEnglishDictionary) Search(query string) (*Word, error) {
+	return oe.SearchContext(context.Background(), query)
+}
+
+// the context is checked before each sense, building the senses with their
+// related words is what takes time in the words with many of them
+func (oe *OpenEnglishDictionary) SearchContext(ctx context.Context, query string) (*Word, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	finded, err := oe.findLexicalEntries(query)
+	if err != nil {
+		return nil, err
+	}
 
 	wordToReturn := NewWord()
-	for _, v := range finded {
+	for _, v := range groupByPartOfSpeech(finded) {
 		var defs []Def = make([]Def, 0)
 		for _, sense := range v.Senses {
-			newDef := Def{
-				Definitions: make([]string, len(sense.Synset.Definitions)),
-				UseExamples: make([]string, len(sense.Synset.Examples)),
-			}
-			for i, definition := range sense.Synset.Definitions {
-				newDef.Definitions[i] = string(definition)
+			if err := ctx.Err(); err != nil {
+				return nil, err
 			}
-			for i, example := range sense.Synset.Examples {
-				newDef.UseExamples[i] = string(example)
-			}
-			defs = append(defs, newDef)
+			defs = append(defs, oe.newDef(v, sense))
 		}
-		wordToReturn.WordDefinitions = append(wordToReturn.WordDefinitions, WordDefinition{
-			WrittenForm:  v.Lemma.WrittenForm,
-			PartOfSpeech: GetPartOfSpeech(v.Lemma.PartOfSpeech),
-			Definitions:  defs,
-		})
+		wordToReturn.WordDefinitions = append(wordToReturn.WordDefinitions, oe.newWordDefinition(v, defs))
 	}
 
 	return wordToReturn, nil
 }
+
+func (oe *OpenEnglishDictionary) newWordDefinition(lexicalEntry *LexicalEntry, defs []Def) WordDefinition {
+	forms := make([]WordForm, len(lexicalEntry.Forms))
+	for i, form := range lexicalEntry.Forms {
+		forms[i] = WordForm{
+			WrittenForm: form.WrittenForm,
+			Tags:        form.Tags,
+		}
+	}
+	pronunciations := make([]string, len(lexicalEntry.Lemma.Pronunciations))
+	for i, pronunciation := range lexicalEntry.Lemma.Pronunciations {
+		pronunciations[i] = string(pronunciation)
+	}
+	return WordDefinition{
+		WrittenForm:    lexicalEntry.Lemma.WrittenForm,
+		PartOfSpeech:   lexicalEntry.Lemma.PartOfSpeech,
+		Tags:           lexicalEntry.Lemma.Tags,
+		Pronunciations: pronunciations,
+		Forms:          forms,
+		Definitions:    defs,
+	}
+}
+
+func (oe *OpenEnglishDictionary) newDef(lexicalEntry *LexicalEntry, sense *Sense) Def {
+	newDef := Def{
+		SynsetId:    sense.Synset.Id,
+		Definitions: make([]string, len(sense.Synset.Definitions)),
+		UseExamples: make([]UseExample, 0, len(sense.Examples)+len(sense.Synset.Examples)),
+	}
+	for i, definition := range sense.Synset.Definitions {
+		newDef.Definitions[i] = string(definition)
+	}
+	if sense.Synset.ILIDefinitions != nil {
+		newDef.ILIDefinition = string(*sense.Synset.ILIDefinitions)
+	}
+	newDef.UseExamples = appendUseExamples(newDef.UseExamples, sense.Examples, ExampleSourceSense)
+	newDef.UseExamples = appendUseExamples(newDef.UseExamples, sense.Synset.Examples, ExampleSourceSynset)
+	newDef.Frames = make([]string, len(sense.SyntacticBehaviours))
+	for i, behaviour := range sense.SyntacticBehaviours {
+		newDef.Frames[i] = behaviour.SubCategorizationFrame
+	}
+	newDef.Related = oe.groupRelatedWords(sense)
+	newDef.Entails = oe.relatedSynsetWords(sense.Synset, RelationTypeEntails)
+	newDef.Causes = oe.relatedSynsetWords(sense.Synset, RelationTypeCauses)
+	newDef.Synonyms = make([]string, 0)
+	for _, synonym := range oe.synsetWords(sense.Synset) {
+		if synonym != lexicalEntry.Lemma.WrittenForm {
+			newDef.Synonyms = append(newDef.Synonyms, synonym)
+		}
+	}
+	return newDef
+}
+
+// append the examples skipping the ones already present, the same sentence
+// is sometimes repeated at the sense and at the synset level
+func appendUseExamples(useExamples []UseExample, examples []Example, source ExampleSource) []UseExample {
+	for _, example := range examples {
+		duplicated := false
+		for _, useExample := range useExamples {
+			if useExample.Text == string(example) {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			useExamples = append(useExamples, UseExample{Text: string(example), Source: source})
+		}
+	}
+	return useExamples
+}
+
+func (oe *OpenEnglishDictionary) Suggest(prefix string, limit int) []string {
+	var suggestions []string = make([]string, 0)
+	start := sort.SearchStrings(oe.sortedWords, prefix)
+	for _, word := range oe.sortedWords[start:] {
+		if (limit > 0 && len(suggestions) == limit) || !strings.HasPrefix(word, prefix) {
+			break
+		}
+		suggestions = append(suggestions, word)
+	}
+	return suggestions
+}
+
+func (oe *OpenEnglishDictionary) Synset(id string) (*SynsetInfo, error) {
+	synset, ok := oe.synsetIdToSynset[id]
+	if !ok {
+		return nil, ErrSynsetNotFound
+	}
+	info := &SynsetInfo{
+		Id:          synset.Id,
+		ILI:         synset.ILI,
+		Words:       oe.synsetWords(synset),
+		Definitions: make([]string, len(synset.Definitions)),
+		Examples:    make([]string, len(synset.Examples)),
+		Relations:   make([]SynsetRelationInfo, 0, len(synset.SynsetRelations)),
+	}
+	for i, definition := range synset.Definitions {
+		info.Definitions[i] = string(definition)
+	}
+	if synset.ILIDefinitions != nil {
+		info.ILIDefinition = string(*synset.ILIDefinitions)
+	}
+	for i, example := range synset.Examples {
+		info.Examples[i] = string(example)
+	}
+	for _, synsetRelation := range synset.SynsetRelations {
+		if synsetRelation.Target == nil {
+			continue
+		}
+		info.Relations = append(info.Relations, SynsetRelationInfo{
+			Relation: synsetRelation.RelType,
+			SynsetId: synsetRelation.Target.Id,
+			Words:    oe.synsetWords(synsetRelation.Target),
+		})
+	}
+	return info, nil
+}
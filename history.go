@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maximum number of lookups kept in the history file
+const maxHistoryEntries = 500
+
+// words successfully looked up, oldest first, with a cursor for the back and
+// forward navigation, the entries are saved one per line in the file
+type History struct {
+	path    string
+	entries []string
+	// index of the word being shown, len(entries) when no history word is shown
+	position int
+}
+
+func historyPath() string {
+	return filepath.Join(stateDir(), "history")
+}
+
+// load the history from the file, a missing file gives an empty history
+func LoadHistory(path string) (*History, error) {
+	h := &History{path: path, entries: make([]string, 0)}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" {
+			h.entries = append(h.entries, word)
+		}
+	}
+	h.trim()
+	h.position = len(h.entries)
+	return h, scanner.Err()
+}
+
+func (h *History) trim() {
+	if len(h.entries) > maxHistoryEntries {
+		h.entries = h.entries[len(h.entries)-maxHistoryEntries:]
+	}
+}
+
+// record a lookup and move the cursor to it, repeating the last word does
+// nothing, the file is rewritten when the history has a path
+func (h *History) Add(word string) error {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil
+	}
+	if len(h.entries) == 0 || h.entries[len(h.entries)-1] != word {
+		h.entries = append(h.entries, word)
+		h.trim()
+	}
+	h.position = len(h.entries) - 1
+	return h.Save()
+}
+
+// move the cursor to the previous word, false at the oldest one
+func (h *History) Back() (string, bool) {
+	if h.position == 0 || len(h.entries) == 0 {
+		return "", false
+	}
+	h.position--
+	return h.entries[h.position], true
+}
+
+// move the cursor to the next word, false at the newest one
+func (h *History) Forward() (string, bool) {
+	if h.position >= len(h.entries)-1 {
+		return "", false
+	}
+	h.position++
+	return h.entries[h.position], true
+}
+
+// return until limit distinct words, the most recent first, without limit
+// if limit <= 0
+func (h *History) Recent(limit int) []string {
+	var recent []string = make([]string, 0)
+	var seen map[string]bool = make(map[string]bool)
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if limit > 0 && len(recent) == limit {
+			break
+		}
+		if !seen[h.entries[i]] {
+			seen[h.entries[i]] = true
+			recent = append(recent, h.entries[i])
+		}
+	}
+	return recent
+}
+
+func (h *History) Save() error {
+	if h.path == "" {
+		return nil
+	}
+	var content strings.Builder
+	for _, word := range h.entries {
+		content.WriteString(word)
+		content.WriteString("\n")
+	}
+	return writeFileAtomic(h.path, []byte(content.String()))
+}
@@ -0,0 +1,41 @@
+package main
+
+import "strings"
+
+// suffix replacements of the inflected forms, like the detachment rules of
+// the morphy function of WordNet
+var detachmentRules [][2]string = [][2]string{
+	// nouns
+	{"ses", "s"}, {"xes", "x"}, {"zes", "z"}, {"ches", "ch"}, {"shes", "sh"},
+	{"men", "man"}, {"ies", "y"},
+	// verbs
+	{"es", "e"}, {"es", ""}, {"ed", "e"}, {"ed", ""}, {"ing", "e"}, {"ing", ""},
+	// adjectives
+	{"er", ""}, {"est", ""}, {"er", "e"}, {"est", "e"},
+	// plurals and third person, last as they are the most permissive
+	{"s", ""},
+}
+
+// return the lemma of a token, tried as written, in lower case, as a form of
+// an entry and then removing the inflection suffixes
+func (oe *OpenEnglishDictionary) Lemmatize(token string) (string, bool) {
+	for _, candidate := range []string{token, strings.ToLower(token)} {
+		if _, ok := oe.wordToLexicalEntry[candidate]; ok {
+			return candidate, true
+		}
+		if lemma, ok := oe.alternativeNames[candidate]; ok {
+			return lemma, true
+		}
+	}
+	lower := strings.ToLower(token)
+	for _, rule := range detachmentRules {
+		if !strings.HasSuffix(lower, rule[0]) || len(lower) <= len(rule[0]) {
+			continue
+		}
+		candidate := strings.TrimSuffix(lower, rule[0]) + rule[1]
+		if _, ok := oe.wordToLexicalEntry[candidate]; ok {
+			return candidate, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// time without typing before the query is searched
+const lookupDebounce = 150 * time.Millisecond
+
+// searches the queries in a worker goroutine, a query is searched only when
+// no other one arrives during the delay, the context of a stale query is
+// cancelled so the search stops early and its result is dropped
+type asyncLookup struct {
+	requests chan string
+	cancels  chan struct{}
+	delay    time.Duration
+	// the context is done when the query is stale
+	search func(ctx context.Context, query string) (*Word, error)
+	// called from the worker goroutine with the result of the last query
+	done func(query string, word *Word, err error)
+}
+
+// start the worker, it stops when the context is done
+func newAsyncLookup(ctx context.Context, delay time.Duration, search func(ctx context.Context, query string) (*Word, error), done func(query string, word *Word, err error)) *asyncLookup {
+	al := &asyncLookup{
+		requests: make(chan string),
+		cancels:  make(chan struct{}),
+		delay:    delay,
+		search:   search,
+		done:     done,
+	}
+	go al.run(ctx)
+	return al
+}
+
+// schedule the search of the query, replacing the pending one
+func (al *asyncLookup) Request(query string) {
+	al.requests <- query
+}
+
+// drop the pending query and the result of the search in progress, used
+// when the word is shown by other ways
+func (al *asyncLookup) Cancel() {
+	al.cancels <- struct{}{}
+}
+
+func (al *asyncLookup) run(ctx context.Context) {
+	timer := time.NewTimer(al.delay)
+	timer.Stop()
+	var pending string
+	// cancel the search in progress
+	var cancelSearch context.CancelFunc = func() {}
+	defer func() {
+		timer.Stop()
+		cancelSearch()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case query := <-al.requests:
+			cancelSearch()
+			pending = query
+			timer.Reset(al.delay)
+		case <-al.cancels:
+			cancelSearch()
+			timer.Stop()
+		case <-timer.C:
+			searchCtx, cancel := context.WithCancel(ctx)
+			cancelSearch = cancel
+			go func(ctx context.Context, query string) {
+				word, err := al.search(ctx, query)
+				if ctx.Err() != nil {
+					return
+				}
+				al.done(query, word, err)
+			}(searchCtx, pending)
+		}
+	}
+}
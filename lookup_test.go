@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSearchContext(t *testing.T) {
+	dict := newTestDictionary()
+	if _, err := dict.SearchContext(context.Background(), "dog"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if word, err := dict.SearchContext(ctx, "dog"); !errors.Is(err, context.Canceled) || word != nil {
+		t.Errorf("got %v and %v, want the error of the context", word, err)
+	}
+}
+
+func TestAsyncLookupCancelsStaleSearch(t *testing.T) {
+	dict := newTestDictionary()
+	started := make(chan struct{})
+	stale := make(chan error, 1)
+	results := make(chan string, 2)
+	search := func(ctx context.Context, query string) (*Word, error) {
+		if query != "cat" {
+			return dict.SearchContext(ctx, query)
+		}
+		// the search of cat is still in progress when dog is typed
+		close(started)
+		<-ctx.Done()
+		word, err := dict.SearchContext(ctx, query)
+		stale <- err
+		return word, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lookup := newAsyncLookup(ctx, time.Millisecond, search, func(query string, word *Word, err error) {
+		results <- query
+	})
+
+	lookup.Request("cat")
+	<-started
+	lookup.Request("dog")
+	select {
+	case err := <-stale:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v from the stale search, want the error of the context", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the stale search didn't return")
+	}
+	select {
+	case query := <-results:
+		if query != "dog" {
+			t.Errorf("got the result of %q, want dog", query)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the last query wasn't searched")
+	}
+}
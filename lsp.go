@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// number of completion items returned for a prefix
+const lspCompletionLimit = 50
+
+// largest message body accepted, 16 MiB is far more than any document
+const lspMaxContentLength = 16 << 20
+
+// language server over stdio providing hover definitions and completion of
+// the dictionary words for prose documents, the messages are handled one at a
+// time
+type lspServer struct {
+	dict   Dictionary
+	reader *bufio.Reader
+	writer io.Writer
+	// text of the open documents by uri
+	documents map[string]string
+	shutdown  bool
+}
+
+type lspMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspTextDocumentPositionParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Position lspPosition `json:"position"`
+}
+
+type lspDidOpenParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+}
+
+type lspDidChangeParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type lspDidCloseParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+}
+
+type lspHover struct {
+	Contents struct {
+		Kind  string `json:"kind"`
+		Value string `json:"value"`
+	} `json:"contents"`
+	Range lspRange `json:"range"`
+}
+
+type lspCompletionItem struct {
+	Label string `json:"label"`
+	// 1 is the kind Text
+	Kind int `json:"kind"`
+}
+
+type lspCompletionList struct {
+	IsIncomplete bool                `json:"isIncomplete"`
+	Items        []lspCompletionItem `json:"items"`
+}
+
+const (
+	lspErrorParse          = -32700
+	lspErrorMethodNotFound = -32601
+	lspErrorInvalidParams  = -32602
+)
+
+func newLSPServer(dict Dictionary, reader io.Reader, writer io.Writer) *lspServer {
+	return &lspServer{
+		dict:      dict,
+		reader:    bufio.NewReader(reader),
+		writer:    writer,
+		documents: make(map[string]string),
+	}
+}
+
+// handle the messages until the exit notification or the end of the input,
+// the returned status follows the protocol: 0 if shutdown was requested
+// before exit and 1 otherwise
+func (ls *lspServer) run() int {
+	for {
+		message, err := ls.readMessage()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return 1
+			}
+			ls.reply(nil, nil, &lspError{Code: lspErrorParse, Message: err.Error()})
+			continue
+		}
+		if message.Method == "exit" {
+			if ls.shutdown {
+				return 0
+			}
+			return 1
+		}
+		ls.handle(message)
+	}
+}
+
+// read one message framed by the Content-Length header
+func (ls *lspServer) readMessage() (*lspMessage, error) {
+	headers, err := textproto.NewReader(ls.reader).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil || length < 0 || length > lspMaxContentLength {
+		return nil, errors.New("Invalid Content-Length header!")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(ls.reader, body); err != nil {
+		return nil, err
+	}
+	var message lspMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (ls *lspServer) handle(message *lspMessage) {
+	switch message.Method {
+	case "initialize":
+		ls.reply(message.Id, map[string]any{
+			"capabilities": map[string]any{
+				// full document sync
+				"textDocumentSync":   1,
+				"hoverProvider":      true,
+				"completionProvider": map[string]any{},
+			},
+			"serverInfo": map[string]string{"name": "word-def"},
+		}, nil)
+	case "shutdown":
+		ls.shutdown = true
+		ls.reply(message.Id, nil, nil)
+	case "textDocument/didOpen":
+		var params lspDidOpenParams
+		if json.Unmarshal(message.Params, &params) == nil {
+			ls.documents[params.TextDocument.URI] = params.TextDocument.Text
+		}
+	case "textDocument/didChange":
+		var params lspDidChangeParams
+		if json.Unmarshal(message.Params, &params) == nil && len(params.ContentChanges) != 0 {
+			ls.documents[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		}
+	case "textDocument/didClose":
+		var params lspDidCloseParams
+		if json.Unmarshal(message.Params, &params) == nil {
+			delete(ls.documents, params.TextDocument.URI)
+		}
+	case "textDocument/hover":
+		var params lspTextDocumentPositionParams
+		if err := json.Unmarshal(message.Params, &params); err != nil {
+			ls.reply(message.Id, nil, &lspError{Code: lspErrorInvalidParams, Message: err.Error()})
+			return
+		}
+		ls.reply(message.Id, ls.hover(params), nil)
+	case "textDocument/completion":
+		var params lspTextDocumentPositionParams
+		if err := json.Unmarshal(message.Params, &params); err != nil {
+			ls.reply(message.Id, nil, &lspError{Code: lspErrorInvalidParams, Message: err.Error()})
+			return
+		}
+		ls.reply(message.Id, ls.completion(params), nil)
+	default:
+		// the notifications without handler are ignored, the requests are
+		// answered with an error
+		if message.Id != nil {
+			ls.reply(message.Id, nil, &lspError{Code: lspErrorMethodNotFound, Message: "Method not found!"})
+		}
+	}
+}
+
+// the hover is null when there's no word under the cursor or it isn't in
+// the dictionary
+func (ls *lspServer) hover(params lspTextDocumentPositionParams) *lspHover {
+	text, start, end, ok := ls.wordAt(params.TextDocument.URI, params.Position)
+	if !ok {
+		return nil
+	}
+	word, err := ls.dict.Search(text)
+	if err != nil {
+		word, err = ls.dict.Search(strings.ToLower(text))
+	}
+	if err != nil {
+		return nil
+	}
+	hover := &lspHover{}
+	hover.Contents.Kind = "markdown"
+	hover.Contents.Value = markdownText(word)
+	hover.Range = lspRange{
+		Start: lspPosition{Line: params.Position.Line, Character: start},
+		End:   lspPosition{Line: params.Position.Line, Character: end},
+	}
+	return hover
+}
+
+func (ls *lspServer) completion(params lspTextDocumentPositionParams) lspCompletionList {
+	list := lspCompletionList{Items: make([]lspCompletionItem, 0)}
+	text, start, _, ok := ls.wordAt(params.TextDocument.URI, params.Position)
+	if !ok {
+		return list
+	}
+	// only the part of the word before the cursor is completed
+	prefix := string(utf16.Decode(utf16.Encode([]rune(text))[:params.Position.Character-start]))
+	if prefix == "" {
+		return list
+	}
+	suggestions := ls.dict.Suggest(prefix, lspCompletionLimit+1)
+	if len(suggestions) > lspCompletionLimit {
+		suggestions = suggestions[:lspCompletionLimit]
+		list.IsIncomplete = true
+	}
+	for _, suggestion := range suggestions {
+		list.Items = append(list.Items, lspCompletionItem{Label: suggestion, Kind: 1})
+	}
+	return list
+}
+
+// return the word around the position and its start and end characters, the
+// characters are counted in UTF-16 code units as required by the protocol
+func (ls *lspServer) wordAt(uri string, position lspPosition) (string, int, int, bool) {
+	document, ok := ls.documents[uri]
+	if !ok {
+		return "", 0, 0, false
+	}
+	lines := strings.Split(document, "\n")
+	if position.Line < 0 || position.Line >= len(lines) {
+		return "", 0, 0, false
+	}
+	line := utf16.Encode([]rune(strings.TrimRight(lines[position.Line], "\r")))
+	if position.Character < 0 || position.Character > len(line) {
+		return "", 0, 0, false
+	}
+	isWordUnit := func(unit uint16) bool {
+		return unit == '\'' || unit == '-' || unicode.IsLetter(rune(unit)) || utf16.IsSurrogate(rune(unit))
+	}
+	start, end := position.Character, position.Character
+	for start > 0 && isWordUnit(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isWordUnit(line[end]) {
+		end++
+	}
+	// the apostrophes and hyphens around the word aren't part of it
+	for start < end && (line[start] == '\'' || line[start] == '-') {
+		start++
+	}
+	for end > start && (line[end-1] == '\'' || line[end-1] == '-') {
+		end--
+	}
+	// the cursor right after a trimmed apostrophe or hyphen is outside the word
+	if start >= end || position.Character < start || position.Character > end {
+		return "", 0, 0, false
+	}
+	return string(utf16.Decode(line[start:end])), start, end, true
+}
+
+func (ls *lspServer) reply(id json.RawMessage, result any, lspErr *lspError) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	// the result must be present, even if null, unless there's an error
+	var response any = struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Id      json.RawMessage `json:"id"`
+		Result  any             `json:"result"`
+	}{JSONRPC: "2.0", Id: id, Result: result}
+	if lspErr != nil {
+		response = struct {
+			JSONRPC string          `json:"jsonrpc"`
+			Id      json.RawMessage `json:"id"`
+			Error   *lspError       `json:"error"`
+		}{JSONRPC: "2.0", Id: id, Error: lspErr}
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(ls.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
@@ -1,13 +1,45 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+)
 
 func main() {
-	lr, err := ParseLexicalXML("wn.xml")
+	config, warnings, err := LoadConfig(configPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading the config file: %s\n", err)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning in the config file: %s\n", warning)
+	}
+	lr, err := ParseLexicalXML(config.Dictionary)
     if err != nil {
-        fmt.Println("Error parsing the .xml dictionary file!")
+		// stderr, the lsp command uses stdout for the protocol
+		fmt.Fprintf(os.Stderr, "Error parsing the .xml dictionary file: %s\n", err)
     }
 	dict := NewOpenEnglishDictionary(lr)
 
-	initApplication(dict)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "define":
+			os.Exit(runDefineCommand(dict, config, os.Args[2:]))
+		case "annotate":
+			os.Exit(runAnnotateCommand(dict, os.Args[2:]))
+		case "batch":
+			os.Exit(runBatchCommand(dict, config, os.Args[2:]))
+		case "lists":
+			os.Exit(runListsCommand(dict, os.Args[2:]))
+		case "similar":
+			os.Exit(runSimilarCommand(dict, os.Args[2:]))
+		case "serve":
+			os.Exit(runServeCommand(dict, os.Args[2:]))
+		case "dictd":
+			os.Exit(runDictdCommand(dict, os.Args[2:]))
+		case "lsp":
+			os.Exit(runLSPCommand(dict, os.Args[2:]))
+		}
+	}
+
+	initApplication(dict, config)
 }
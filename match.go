@@ -0,0 +1,213 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidStrategy = errors.New("Invalid strategy!")
+
+// strategies used to match the words of the dictionary against a query, all
+// of them ignore the case
+type MatchStrategy struct {
+	Name        string
+	Description string
+	// the query is compiled once and the returned function tests each word
+	compile func(query string) (func(word string) bool, error)
+}
+
+var matchStrategies []MatchStrategy = []MatchStrategy{
+	{
+		Name:        "exact",
+		Description: "Match headwords exactly",
+		compile: func(query string) (func(string) bool, error) {
+			query = strings.ToLower(query)
+			return func(word string) bool { return word == query }, nil
+		},
+	},
+	{
+		Name:        "prefix",
+		Description: "Match prefixes",
+		compile: func(query string) (func(string) bool, error) {
+			query = strings.ToLower(query)
+			return func(word string) bool { return strings.HasPrefix(word, query) }, nil
+		},
+	},
+	{
+		Name:        "substring",
+		Description: "Match substring occurring anywhere in a headword",
+		compile: func(query string) (func(string) bool, error) {
+			query = strings.ToLower(query)
+			return func(word string) bool { return strings.Contains(word, query) }, nil
+		},
+	},
+	{
+		Name:        "regex",
+		Description: "Regular expression search",
+		compile: func(query string) (func(string) bool, error) {
+			// lowercasing the pattern would turn escapes like \D into \d
+			re, err := regexp.Compile("(?i)" + query)
+			if err != nil {
+				return nil, err
+			}
+			return re.MatchString, nil
+		},
+	},
+	{
+		Name:        "soundex",
+		Description: "Match using SOUNDEX algorithm",
+		compile: func(query string) (func(string) bool, error) {
+			query = strings.ToLower(query)
+			code := soundex(query)
+			return func(word string) bool { return code != "" && soundex(word) == code }, nil
+		},
+	},
+	{
+		Name:        "lev",
+		Description: "Match headwords within Levenshtein distance one",
+		compile: func(query string) (func(string) bool, error) {
+			query = strings.ToLower(query)
+			return func(word string) bool { return levenshtein(word, query) <= 1 }, nil
+		},
+	},
+}
+
+func findMatchStrategy(name string) (MatchStrategy, bool) {
+	for _, strategy := range matchStrategies {
+		if strategy.Name == name {
+			return strategy, true
+		}
+	}
+	return MatchStrategy{}, false
+}
+
+// return the words of the dictionary matching the query with the strategy,
+// in alphabetical order
+func (oe *OpenEnglishDictionary) Match(query string, strategyName string) ([]string, error) {
+	strategy, ok := findMatchStrategy(strategyName)
+	if !ok {
+		return nil, ErrInvalidStrategy
+	}
+	matches, err := strategy.compile(query)
+	if err != nil {
+		return nil, err
+	}
+	var words []string = make([]string, 0)
+	for _, word := range oe.sortedWords {
+		if matches(strings.ToLower(word)) {
+			words = append(words, word)
+		}
+	}
+	return words, nil
+}
+
+var soundexCodes map[rune]byte = map[rune]byte{
+	'b': '1', 'f': '1', 'p': '1', 'v': '1',
+	'c': '2', 'g': '2', 'j': '2', 'k': '2', 'q': '2', 's': '2', 'x': '2', 'z': '2',
+	'd': '3', 't': '3',
+	'l': '4',
+	'm': '5', 'n': '5',
+	'r': '6',
+}
+
+// american soundex code of the word, empty if the word has no letters
+func soundex(word string) string {
+	var code []byte = make([]byte, 0, 4)
+	var last byte
+	for _, r := range strings.ToLower(word) {
+		if r < 'a' || r > 'z' {
+			continue
+		}
+		digit := soundexCodes[r]
+		if len(code) == 0 {
+			code = append(code, byte(r-'a'+'A'))
+			last = digit
+			continue
+		}
+		// h and w don't separate letters with the same code, vowels do
+		if r == 'h' || r == 'w' {
+			continue
+		}
+		if digit != 0 && digit != last {
+			code = append(code, digit)
+			if len(code) == 4 {
+				break
+			}
+		}
+		last = digit
+	}
+	if len(code) == 0 {
+		return ""
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// edit distance between the words counting insertions, deletions and
+// substitutions of runes
+func levenshtein(a string, b string) int {
+	runesA, runesB := []rune(a), []rune(b)
+	previous := make([]int, len(runesB)+1)
+	current := make([]int, len(runesB)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(runesA); i++ {
+		current[0] = i
+		for j := 1; j <= len(runesB); j++ {
+			cost := 1
+			if runesA[i-1] == runesB[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(runesB)]
+}
+
+// maximum edit distance of the corrections suggested for a word not found
+const maxCorrectionDistance = 2
+
+// return until limit words of the dictionary close to the query, the closest
+// first, to suggest corrections for words not found
+func (oe *OpenEnglishDictionary) Corrections(query string, limit int) []string {
+	query = strings.ToLower(query)
+	type correction struct {
+		word     string
+		distance int
+	}
+	var corrections []correction = make([]correction, 0)
+	for _, word := range oe.sortedWords {
+		// the distance can't be smaller than the difference of the lengths
+		if abs(len(word)-len(query)) > maxCorrectionDistance {
+			continue
+		}
+		distance := levenshtein(strings.ToLower(word), query)
+		if distance <= maxCorrectionDistance {
+			corrections = append(corrections, correction{word: word, distance: distance})
+		}
+	}
+	sort.SliceStable(corrections, func(i, j int) bool {
+		return corrections[i].distance < corrections[j].distance
+	})
+	var words []string = make([]string, 0, limit)
+	for _, c := range corrections {
+		if len(words) == limit {
+			break
+		}
+		words = append(words, c.word)
+	}
+	return words
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
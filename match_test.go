@@ -0,0 +1,33 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	dict := newTestDictionary()
+	tests := []struct {
+		query    string
+		strategy string
+		want     []string
+	}{
+		{"DOG", "exact", []string{"dog"}},
+		{"Ca", "prefix", []string{"cat"}},
+		{"O", "substring", []string{"dog"}},
+		{`c\Dt`, "regex", []string{"cat"}},
+		{`c\dt`, "regex", []string{}},
+		{`^D`, "regex", []string{"dog"}},
+		{"DOGS", "lev", []string{"dog"}},
+	}
+	for _, test := range tests {
+		words, err := dict.Match(test.query, test.strategy)
+		if err != nil {
+			t.Errorf("Match(%q, %q): %s", test.query, test.strategy, err)
+			continue
+		}
+		if !reflect.DeepEqual(words, test.want) {
+			t.Errorf("Match(%q, %q): got %v, want %v", test.query, test.strategy, words, test.want)
+		}
+	}
+}
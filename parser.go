@@ -3,8 +3,10 @@ package main
 import (
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"strings"
 )
 
 type LexicalResource struct {
@@ -71,6 +73,8 @@ func NewLexicalEntry() *LexicalEntry {
 type Synset struct {
 	Id              string
 	ILI             string
+	// zero when the synset doesn't have the attribute
+	PartOfSpeech    PartOfSpeech
 	Definitions     []Definition
 	ILIDefinitions  *ILIDefinition
 	SynsetRelations []*SynsetRelation
@@ -81,6 +85,7 @@ func NewSynset() *Synset {
 	return &Synset{
 		Id:              "",
 		ILI:             "",
+		PartOfSpeech:    0,
 		Definitions:     make([]Definition, 0),
 		ILIDefinitions:  nil,
 		SynsetRelations: make([]*SynsetRelation, 0),
@@ -94,7 +99,7 @@ type ILIDefinition string
 
 type Lemma struct {
 	WrittenForm    string
-	PartOfSpeech   rune
+	PartOfSpeech   PartOfSpeech
 	Pronunciations []Pronunciation
 	Tags           []Tag
 }
@@ -129,6 +134,9 @@ type Sense struct {
 	SenseRelations []*SenseRelation
 	Examples       []Example
 	Counts         []Count
+	// behaviours linked by the subcat attribute of the sense or by the senses
+	// attribute of the SyntacticBehaviour
+	SyntacticBehaviours []*SyntacticBehaviour
 }
 
 func NewSense() *Sense {
@@ -138,6 +146,7 @@ func NewSense() *Sense {
         SenseRelations: make([]*SenseRelation, 0),
         Examples: make([]Example, 0),
         Counts: make([]Count, 0),
+		SyntacticBehaviours: make([]*SyntacticBehaviour, 0),
     }
 }
 
@@ -145,31 +154,80 @@ type RelationType int8
 
 const ()
 
-func GetPartOfSpeech(pos rune) string {
-	switch pos {
-	case 'n':
-		return "Noun"
-    case 'v':
-        return "Verb"
-    case 'a':
-        return "Adjective"
-    case 'r':
-        return "Adverb"
-    case 's':
-        return "Adjective Satellite"
-    case 'c':
-        return "Conjuction"
-    case 'p':
-        return "Adposition"
-    case 'x':
-        return "Other"
-	default:
-		return "Unknown"
+// part of speech of a lemma or a synset, the value is the abbreviation used
+// by the partOfSpeech attribute
+type PartOfSpeech rune
+
+const (
+	PartOfSpeechNoun               = PartOfSpeech('n')
+	PartOfSpeechVerb               = PartOfSpeech('v')
+	PartOfSpeechAdjective          = PartOfSpeech('a')
+	PartOfSpeechAdverb             = PartOfSpeech('r')
+	PartOfSpeechAdjectiveSatellite = PartOfSpeech('s')
+	PartOfSpeechPhrase             = PartOfSpeech('t')
+	PartOfSpeechConjunction        = PartOfSpeech('c')
+	PartOfSpeechAdposition         = PartOfSpeech('p')
+	PartOfSpeechOther              = PartOfSpeech('x')
+	PartOfSpeechUnknown            = PartOfSpeech('u')
+)
+
+var partOfSpeechLongNames map[PartOfSpeech]string = map[PartOfSpeech]string{
+	PartOfSpeechNoun:               "Noun",
+	PartOfSpeechVerb:               "Verb",
+	PartOfSpeechAdjective:          "Adjective",
+	PartOfSpeechAdverb:             "Adverb",
+	PartOfSpeechAdjectiveSatellite: "Adjective Satellite",
+	PartOfSpeechPhrase:             "Phrase",
+	PartOfSpeechConjunction:        "Conjunction",
+	PartOfSpeechAdposition:         "Adposition",
+	PartOfSpeechOther:              "Other",
+	PartOfSpeechUnknown:            "Unknown",
+}
+
+// parse the value of a partOfSpeech attribute, only the abbreviations of the
+// DTD are valid
+func ParsePartOfSpeech(abbreviation string) (PartOfSpeech, error) {
+	pos := PartOfSpeech(0)
+	if runes := []rune(abbreviation); len(runes) == 1 {
+		pos = PartOfSpeech(runes[0])
+	}
+	if !pos.IsValid() {
+		return 0, fmt.Errorf("Invalid part of speech %q!", abbreviation)
 	}
+	return pos, nil
+}
+
+func (pos PartOfSpeech) IsValid() bool {
+	_, ok := partOfSpeechLongNames[pos]
+	return ok
+}
+
+// return the partOfSpeech attribute value of the part of speech
+func (pos PartOfSpeech) Abbreviation() string {
+	return string(pos)
+}
+
+// return the long name of the part of speech, like "Noun", empty for the zero
+// value and "Invalid" for the values that aren't parts of speech
+func (pos PartOfSpeech) String() string {
+	if pos == 0 {
+		return ""
+	}
+	name, ok := partOfSpeechLongNames[pos]
+	if !ok {
+		return "Invalid"
+	}
+	return name
+}
+
+func (pos PartOfSpeech) MarshalText() ([]byte, error) {
+	return []byte(pos.String()), nil
 }
 
 const (
-	RelationTypeAntonym = RelationType(iota)
+	// zero value, the absence of a relation
+	RelationTypeNone = RelationType(iota)
+	RelationTypeAntonym
 	RelationTypeAlso
 	RelationTypeParticiple
 	RelationTypePertainym
@@ -183,8 +241,210 @@ const (
 	RelationTypeSimilar
 	RelationTypeOther
 	RelationTypeSimpleAspectIp
+	RelationTypeSecondaryAspectIp
+	RelationTypeSimpleAspectPi
+	RelationTypeSecondaryAspectPi
+	RelationTypeFeminine
+	RelationTypeHasFeminine
+	RelationTypeMasculine
+	RelationTypeHasMasculine
+	RelationTypeYoung
+	RelationTypeHasYoung
+	RelationTypeDiminutive
+	RelationTypeHasDiminutive
+	RelationTypeAugmentative
+	RelationTypeHasAugmentative
+	RelationTypeAntoGradable
+	RelationTypeAntoSimple
+	RelationTypeAntoConverse
+	// the types bellow are only used by the synset relations
+	RelationTypeAgent
+	RelationTypeAttribute
+	RelationTypeBeInState
+	RelationTypeCauses
+	RelationTypeClassifiedBy
+	RelationTypeClassifies
+	RelationTypeCoAgentInstrument
+	RelationTypeCoAgentPatient
+	RelationTypeCoAgentResult
+	RelationTypeCoInstrumentAgent
+	RelationTypeCoInstrumentPatient
+	RelationTypeCoInstrumentResult
+	RelationTypeCoPatientAgent
+	RelationTypeCoPatientInstrument
+	RelationTypeCoResultAgent
+	RelationTypeCoResultInstrument
+	RelationTypeCoRole
+	RelationTypeDirection
+	RelationTypeEntails
+	RelationTypeEqSynonym
+	RelationTypeHoloLocation
+	RelationTypeHoloMember
+	RelationTypeHoloPart
+	RelationTypeHoloPortion
+	RelationTypeHoloSubstance
+	RelationTypeHolonym
+	RelationTypeHypernym
+	RelationTypeHyponym
+	RelationTypeInManner
+	RelationTypeInstanceHypernym
+	RelationTypeInstanceHyponym
+	RelationTypeInstrument
+	RelationTypeInvolved
+	RelationTypeInvolvedAgent
+	RelationTypeInvolvedDirection
+	RelationTypeInvolvedInstrument
+	RelationTypeInvolvedLocation
+	RelationTypeInvolvedPatient
+	RelationTypeInvolvedResult
+	RelationTypeInvolvedSourceDirection
+	RelationTypeInvolvedTargetDirection
+	RelationTypeIsCausedBy
+	RelationTypeIsEntailedBy
+	RelationTypeLocation
+	RelationTypeMannerOf
+	RelationTypeMeroLocation
+	RelationTypeMeroMember
+	RelationTypeMeroPart
+	RelationTypeMeroPortion
+	RelationTypeMeroSubstance
+	RelationTypeMeronym
+	RelationTypePatient
+	RelationTypeRestrictedBy
+	RelationTypeRestricts
+	RelationTypeResult
+	RelationTypeRole
+	RelationTypeSourceDirection
+	RelationTypeStateOf
+	RelationTypeTargetDirection
+	RelationTypeSubevent
+	RelationTypeIsSubeventOf
+	RelationTypeIrSynonym
 )
 
+// link the values of the relType attribute to the RelationType
+var relationTypeNames map[string]RelationType = map[string]RelationType{
+	"antonym":                   RelationTypeAntonym,
+	"also":                      RelationTypeAlso,
+	"participle":                RelationTypeParticiple,
+	"pertainym":                 RelationTypePertainym,
+	"derivation":                RelationTypeDerivation,
+	"domain_topic":              RelationTypeDomainTopic,
+	"has_domain_topic":          RelationTypeHasDomainTopic,
+	"domain_region":             RelationTypeDomainRegion,
+	"has_domain_region":         RelationTypeHasDomainRegion,
+	"exemplifies":               RelationTypeExemplifies,
+	"is_exemplified_by":         RelationTypeIsExemplifiedBy,
+	"similar":                   RelationTypeSimilar,
+	"other":                     RelationTypeOther,
+	"simple_aspect_ip":          RelationTypeSimpleAspectIp,
+	"secondary_aspect_ip":       RelationTypeSecondaryAspectIp,
+	"simple_aspect_pi":          RelationTypeSimpleAspectPi,
+	"secondary_aspect_pi":       RelationTypeSecondaryAspectPi,
+	"feminine":                  RelationTypeFeminine,
+	"has_feminine":              RelationTypeHasFeminine,
+	"masculine":                 RelationTypeMasculine,
+	"has_masculine":             RelationTypeHasMasculine,
+	"young":                     RelationTypeYoung,
+	"has_young":                 RelationTypeHasYoung,
+	"diminutive":                RelationTypeDiminutive,
+	"has_diminutive":            RelationTypeHasDiminutive,
+	"augmentative":              RelationTypeAugmentative,
+	"has_augmentative":          RelationTypeHasAugmentative,
+	"anto_gradable":             RelationTypeAntoGradable,
+	"anto_simple":               RelationTypeAntoSimple,
+	"anto_converse":             RelationTypeAntoConverse,
+	"agent":                     RelationTypeAgent,
+	"attribute":                 RelationTypeAttribute,
+	"be_in_state":               RelationTypeBeInState,
+	"causes":                    RelationTypeCauses,
+	"classified_by":             RelationTypeClassifiedBy,
+	"classifies":                RelationTypeClassifies,
+	"co_agent_instrument":       RelationTypeCoAgentInstrument,
+	"co_agent_patient":          RelationTypeCoAgentPatient,
+	"co_agent_result":           RelationTypeCoAgentResult,
+	"co_instrument_agent":       RelationTypeCoInstrumentAgent,
+	"co_instrument_patient":     RelationTypeCoInstrumentPatient,
+	"co_instrument_result":      RelationTypeCoInstrumentResult,
+	"co_patient_agent":          RelationTypeCoPatientAgent,
+	"co_patient_instrument":     RelationTypeCoPatientInstrument,
+	"co_result_agent":           RelationTypeCoResultAgent,
+	"co_result_instrument":      RelationTypeCoResultInstrument,
+	"co_role":                   RelationTypeCoRole,
+	"direction":                 RelationTypeDirection,
+	"entails":                   RelationTypeEntails,
+	"eq_synonym":                RelationTypeEqSynonym,
+	"holo_location":             RelationTypeHoloLocation,
+	"holo_member":               RelationTypeHoloMember,
+	"holo_part":                 RelationTypeHoloPart,
+	"holo_portion":              RelationTypeHoloPortion,
+	"holo_substance":            RelationTypeHoloSubstance,
+	"holonym":                   RelationTypeHolonym,
+	"hypernym":                  RelationTypeHypernym,
+	"hyponym":                   RelationTypeHyponym,
+	"in_manner":                 RelationTypeInManner,
+	"instance_hypernym":         RelationTypeInstanceHypernym,
+	"instance_hyponym":          RelationTypeInstanceHyponym,
+	"instrument":                RelationTypeInstrument,
+	"involved":                  RelationTypeInvolved,
+	"involved_agent":            RelationTypeInvolvedAgent,
+	"involved_direction":        RelationTypeInvolvedDirection,
+	"involved_instrument":       RelationTypeInvolvedInstrument,
+	"involved_location":         RelationTypeInvolvedLocation,
+	"involved_patient":          RelationTypeInvolvedPatient,
+	"involved_result":           RelationTypeInvolvedResult,
+	"involved_source_direction": RelationTypeInvolvedSourceDirection,
+	"involved_target_direction": RelationTypeInvolvedTargetDirection,
+	"is_caused_by":              RelationTypeIsCausedBy,
+	"is_entailed_by":            RelationTypeIsEntailedBy,
+	"location":                  RelationTypeLocation,
+	"manner_of":                 RelationTypeMannerOf,
+	"mero_location":             RelationTypeMeroLocation,
+	"mero_member":               RelationTypeMeroMember,
+	"mero_part":                 RelationTypeMeroPart,
+	"mero_portion":              RelationTypeMeroPortion,
+	"mero_substance":            RelationTypeMeroSubstance,
+	"meronym":                   RelationTypeMeronym,
+	"patient":                   RelationTypePatient,
+	"restricted_by":             RelationTypeRestrictedBy,
+	"restricts":                 RelationTypeRestricts,
+	"result":                    RelationTypeResult,
+	"role":                      RelationTypeRole,
+	"source_direction":          RelationTypeSourceDirection,
+	"state_of":                  RelationTypeStateOf,
+	"target_direction":          RelationTypeTargetDirection,
+	"subevent":                  RelationTypeSubevent,
+	"is_subevent_of":            RelationTypeIsSubeventOf,
+	"ir_synonym":                RelationTypeIrSynonym,
+}
+
+// unknown relation types are mapped to RelationTypeOther
+func ParseRelationType(relType string) RelationType {
+	relationType, ok := relationTypeNames[relType]
+	if !ok {
+		return RelationTypeOther
+	}
+	return relationType
+}
+
+// return the relType attribute value of the relation type, empty for
+// RelationTypeNone
+func (rt RelationType) String() string {
+	if rt == RelationTypeNone {
+		return ""
+	}
+	for name, relationType := range relationTypeNames {
+		if relationType == rt {
+			return name
+		}
+	}
+	return "other"
+}
+
+func (rt RelationType) MarshalText() ([]byte, error) {
+	return []byte(rt.String()), nil
+}
+
 type SenseRelation struct {
 	// reference to an Sense
 	Target  *Sense
@@ -194,7 +454,7 @@ type SenseRelation struct {
 func NewSenseRelation(target *Sense, reltype string) *SenseRelation{
     return &SenseRelation{
         Target: target,
-        RelType: RelationTypeAlso,
+        RelType: ParseRelationType(reltype),
     }
 }
 
@@ -205,11 +465,12 @@ type Count string
 type Pronunciation string
 
 type Tag struct {
-	Category string
-	Value    string
+	Category string `json:"category"`
+	Value    string `json:"value"`
 }
 
 type SyntacticBehaviour struct {
+	Id                     string
 	SubCategorizationFrame string
 }
 
@@ -222,7 +483,7 @@ type SynsetRelation struct {
 func NewSynsetRelation(target *Synset, reltype string) *SynsetRelation{
     return &SynsetRelation{
         Target: target,
-        RelType: RelationTypeAlso,
+        RelType: ParseRelationType(reltype),
     }
 
 }
@@ -270,6 +531,9 @@ func ParseLexicalXML(filename string) (*LexicalResource, error) {
     var tempSenseIdToLinkedsSenseRelation map[string][]*SenseRelation = make(map[string][]*SenseRelation, 100000)
     var tempSynsetIdToLinkedsSynsetRelation map[string][]*SynsetRelation = make(map[string][]*SynsetRelation, 100000)
     var tempSenseIDToSense map[string]*Sense = make(map[string]*Sense, 10000)
+	var tempSyntacticBehaviourIdToSyntacticBehaviour map[string]*SyntacticBehaviour = make(map[string]*SyntacticBehaviour, 100)
+	var tempSenseIdToSubcatIds map[string][]string = make(map[string][]string, 10000)
+	var tempSenseIdToLinkedsSyntacticBehaviour map[string][]*SyntacticBehaviour = make(map[string][]*SyntacticBehaviour, 10000)
 
 	for {
 		nextToken, decodeErr := xmlDecoder.Token()
@@ -324,7 +588,12 @@ func ParseLexicalXML(filename string) (*LexicalResource, error) {
 					if attr.Name.Local == "writtenForm" {
 						nextLemma.WrittenForm = attr.Value
 					} else if attr.Name.Local == "partOfSpeech" {
-						nextLemma.PartOfSpeech = []rune(attr.Value)[0]
+						pos, err := ParsePartOfSpeech(attr.Value)
+						if err != nil {
+							line, column := xmlDecoder.InputPos()
+							return nil, fmt.Errorf("Lexical entry %q at line %d, column %d: %w", nextLexicalEntry.Id, line, column, err)
+						}
+						nextLemma.PartOfSpeech = pos
 					}
 				}
 			} else if elementName == "Form" {
@@ -349,27 +618,55 @@ func ParseLexicalXML(filename string) (*LexicalResource, error) {
 
 			} else if elementName == "SyntacticBehaviour" {
 				nextSyntacticBehaviour = &SyntacticBehaviour{}
+				var senseIds []string
 				for _, attr := range v.Attr {
-					if attr.Name.Local == "subcategorizationFrame" {
+					if attr.Name.Local == "id" {
+						nextSyntacticBehaviour.Id = attr.Value
+						tempSyntacticBehaviourIdToSyntacticBehaviour[attr.Value] = nextSyntacticBehaviour
+					} else if attr.Name.Local == "subcategorizationFrame" {
 						nextSyntacticBehaviour.SubCategorizationFrame = attr.Value
+					} else if attr.Name.Local == "senses" {
+						senseIds = strings.Fields(attr.Value)
 					}
 				}
+				for _, senseId := range senseIds {
+					tempSenseIdToLinkedsSyntacticBehaviour[senseId] = append(tempSenseIdToLinkedsSyntacticBehaviour[senseId], nextSyntacticBehaviour)
+				}
 				if insideLexicalEntry {
 					nextLexicalEntry.SyntaticBehaviours = append(nextLexicalEntry.SyntaticBehaviours, *nextSyntacticBehaviour)
+					// without the senses attribute the behaviour applies to
+					// every sense of the entry
+					if len(senseIds) == 0 {
+						for _, sense := range nextLexicalEntry.Senses {
+							sense.SyntacticBehaviours = append(sense.SyntacticBehaviours, nextSyntacticBehaviour)
+						}
+					}
 				} else if insideLexicon {
 					nextLexicon.SyntacticBehaviours = append(nextLexicon.SyntacticBehaviours, nextSyntacticBehaviour)
 				}
 			} else if elementName == "Synset" {
                 insideSynset = true
 				nextSynset = NewSynset()
+				var partOfSpeech *string
 				for _, attr := range v.Attr {
 					if attr.Name.Local == "id" {
 						nextSynset.Id = attr.Value
 					} else if attr.Name.Local == "ili" {
 						nextSynset.ILI = attr.Value
+					} else if attr.Name.Local == "partOfSpeech" {
+						partOfSpeech = &attr.Value
 					}
 
 				}
+				// parsed after the loop so the error has the id of the synset
+				if partOfSpeech != nil {
+					pos, err := ParsePartOfSpeech(*partOfSpeech)
+					if err != nil {
+						line, column := xmlDecoder.InputPos()
+						return nil, fmt.Errorf("Synset %q at line %d, column %d: %w", nextSynset.Id, line, column, err)
+					}
+					nextSynset.PartOfSpeech = pos
+				}
 			} else if elementName == "Definition" {
 				insideDefinition = true
 			} else if elementName == "ILIDefinition" {
@@ -398,6 +695,7 @@ func ParseLexicalXML(filename string) (*LexicalResource, error) {
                 insideSense = true
                 nextSense = NewSense()
                 var synsetId string
+				var subcatIds []string
 				for _, attr := range v.Attr {
 					if attr.Name.Local == "id" {
                         nextSense.Id = attr.Value
@@ -405,9 +703,14 @@ func ParseLexicalXML(filename string) (*LexicalResource, error) {
 					} else if attr.Name.Local == "synset" {
                         nextSense.Synset = nil
                         synsetId = attr.Value
+					} else if attr.Name.Local == "subcat" {
+						subcatIds = strings.Fields(attr.Value)
 					}
 				}
                 tempSenseIdToSynsetId[nextSense.Id] = synsetId
+				if len(subcatIds) != 0 {
+					tempSenseIdToSubcatIds[nextSense.Id] = subcatIds
+				}
 
             } else if elementName == "SenseRelation" {
                 var relType string
@@ -532,6 +835,24 @@ func ParseLexicalXML(filename string) (*LexicalResource, error) {
         }
     }
 
+    // fill the field SyntacticBehaviours in Sense, first the behaviours
+    // referenced by the sense and then the ones referencing the sense
+    for senseID, behaviourIDs := range tempSenseIdToSubcatIds {
+        sense := tempSenseIDToSense[senseID]
+        for _, behaviourID := range behaviourIDs {
+            behaviour, ok := tempSyntacticBehaviourIdToSyntacticBehaviour[behaviourID]
+            if ok {
+                sense.SyntacticBehaviours = append(sense.SyntacticBehaviours, behaviour)
+            }
+        }
+    }
+    for senseID, behaviours := range tempSenseIdToLinkedsSyntacticBehaviour {
+        sense, ok := tempSenseIDToSense[senseID]
+        if ok {
+            sense.SyntacticBehaviours = append(sense.SyntacticBehaviours, behaviours...)
+        }
+    }
+
     // fill the field Synset in Sense
     for senseID, synsetID := range tempSenseIdToSynsetId {
         sense := tempSenseIDToSense[senseID]
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLexicalXMLInvalidPartOfSpeech(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+		want string
+	}{
+		{"lemma", `<LexicalEntry id="test-dog-q"><Lemma writtenForm="dog" partOfSpeech="q"/></LexicalEntry>`, `Lexical entry "test-dog-q" at line 3`},
+		{"synset", `<Synset partOfSpeech="nn" id="test-dog-n"/>`, `Synset "test-dog-n" at line 3`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "wn.xml")
+			content := "<LexicalResource>\n<Lexicon id=\"test\">\n" + test.xml + "\n</Lexicon>\n</LexicalResource>\n"
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ParseLexicalXML(path)
+			if err == nil {
+				t.Fatal("got no error for an invalid part of speech")
+			}
+			if !strings.Contains(err.Error(), test.want) || !strings.Contains(err.Error(), "Invalid part of speech") {
+				t.Errorf("got error %q, want it to contain %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestPartOfSpeechString(t *testing.T) {
+	tests := []struct {
+		pos  PartOfSpeech
+		want string
+	}{
+		{PartOfSpeechNoun, "Noun"},
+		{PartOfSpeechPhrase, "Phrase"},
+		{PartOfSpeechUnknown, "Unknown"},
+		{0, ""},
+		{PartOfSpeech('q'), "Invalid"},
+	}
+	for _, test := range tests {
+		if got := test.pos.String(); got != test.want {
+			t.Errorf("PartOfSpeech(%q).String(): got %q, want %q", rune(test.pos), got, test.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// abbreviations and names of the parts of speech accepted by the filters,
+// besides the abbreviations and the long names
+var partOfSpeechNames map[string]PartOfSpeech = map[string]PartOfSpeech{
+	"adj": PartOfSpeechAdjective, "satellite": PartOfSpeechAdjectiveSatellite,
+	"adv": PartOfSpeechAdverb, "conj": PartOfSpeechConjunction,
+	"adp": PartOfSpeechAdposition,
+}
+
+// order of the parts of speech in the results, the satellites are grouped
+// with the adjectives
+var partOfSpeechOrder map[PartOfSpeech]int = map[PartOfSpeech]int{
+	PartOfSpeechNoun: 0, PartOfSpeechVerb: 1, PartOfSpeechAdjective: 2, PartOfSpeechAdjectiveSatellite: 2,
+	PartOfSpeechAdverb: 3, PartOfSpeechPhrase: 4, PartOfSpeechConjunction: 5, PartOfSpeechAdposition: 6,
+	PartOfSpeechOther: 7, PartOfSpeechUnknown: 8,
+}
+
+// find the part of speech by its abbreviation, its long name or one of the
+// names of partOfSpeechNames, ignoring the case
+func lookupPartOfSpeech(name string) (PartOfSpeech, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if pos, err := ParsePartOfSpeech(name); err == nil {
+		return pos, true
+	}
+	for pos, longName := range partOfSpeechLongNames {
+		if strings.ToLower(longName) == name {
+			return pos, true
+		}
+	}
+	pos, ok := partOfSpeechNames[name]
+	return pos, ok
+}
+
+// parse a comma separated list of parts of speech, like "n,v" or "verb"
+func ParsePartsOfSpeech(text string) ([]PartOfSpeech, error) {
+	var partsOfSpeech []PartOfSpeech = make([]PartOfSpeech, 0)
+	for _, name := range strings.Split(text, ",") {
+		pos, ok := lookupPartOfSpeech(name)
+		if !ok {
+			return nil, fmt.Errorf("Invalid part of speech %q!", name)
+		}
+		partsOfSpeech = append(partsOfSpeech, pos)
+	}
+	return partsOfSpeech, nil
+}
+
+// split a query like "run:v" in the word and the parts of speech, false when
+// the query doesn't end with a valid filter
+func splitPartOfSpeechFilter(query string) (string, []PartOfSpeech, bool) {
+	i := strings.LastIndex(query, ":")
+	if i <= 0 {
+		return query, nil, false
+	}
+	partsOfSpeech, err := ParsePartsOfSpeech(query[i+1:])
+	if err != nil {
+		return query, nil, false
+	}
+	return query[:i], partsOfSpeech, true
+}
+
+// add the filter to the query, as the "word:pos" syntax of Search
+func withPartOfSpeechFilter(query string, partsOfSpeech []PartOfSpeech) string {
+	if len(partsOfSpeech) == 0 {
+		return query
+	}
+	var abbreviations []string = make([]string, len(partsOfSpeech))
+	for i, pos := range partsOfSpeech {
+		abbreviations[i] = pos.Abbreviation()
+	}
+	return query + ":" + strings.Join(abbreviations, ",")
+}
+
+// tell if the part of speech passes the filter, an empty filter passes
+// everything and the adjectives include the satellites
+func matchPartOfSpeech(pos PartOfSpeech, partsOfSpeech []PartOfSpeech) bool {
+	if len(partsOfSpeech) == 0 {
+		return true
+	}
+	for _, filter := range partsOfSpeech {
+		if pos == filter || (filter == PartOfSpeechAdjective && pos == PartOfSpeechAdjectiveSatellite) {
+			return true
+		}
+	}
+	return false
+}
+
+func filterLexicalEntries(lexicalEntries []*LexicalEntry, partsOfSpeech []PartOfSpeech) []*LexicalEntry {
+	var filtered []*LexicalEntry = make([]*LexicalEntry, 0, len(lexicalEntries))
+	for _, lexicalEntry := range lexicalEntries {
+		if matchPartOfSpeech(lexicalEntry.Lemma.PartOfSpeech, partsOfSpeech) {
+			filtered = append(filtered, lexicalEntry)
+		}
+	}
+	return filtered
+}
+
+// sort the lexical entries by part of speech, keeping the dictionary order
+// inside each one
+func groupByPartOfSpeech(lexicalEntries []*LexicalEntry) []*LexicalEntry {
+	var grouped []*LexicalEntry = make([]*LexicalEntry, len(lexicalEntries))
+	copy(grouped, lexicalEntries)
+	sort.SliceStable(grouped, func(i, j int) bool {
+		return partOfSpeechOrder[grouped[i].Lemma.PartOfSpeech] < partOfSpeechOrder[grouped[j].Lemma.PartOfSpeech]
+	})
+	return grouped
+}
+
+// number of senses of each part of speech of the word, like "Noun 2, Verb 3",
+// in the order of the word definitions
+func partOfSpeechCounts(word *Word) string {
+	var counts map[PartOfSpeech]int = make(map[PartOfSpeech]int)
+	var partsOfSpeech []PartOfSpeech = make([]PartOfSpeech, 0)
+	for _, wordDefinition := range word.WordDefinitions {
+		if _, ok := counts[wordDefinition.PartOfSpeech]; !ok {
+			partsOfSpeech = append(partsOfSpeech, wordDefinition.PartOfSpeech)
+		}
+		counts[wordDefinition.PartOfSpeech] += len(wordDefinition.Definitions)
+	}
+	var texts []string = make([]string, len(partsOfSpeech))
+	for i, pos := range partsOfSpeech {
+		texts[i] = fmt.Sprintf("%s %d", pos, counts[pos])
+	}
+	return strings.Join(texts, ", ")
+}
@@ -0,0 +1,203 @@
+package main
+
+import "sort"
+
+// group of words linked to a word by the same relation type
+type RelatedWords struct {
+	Relation RelationType `json:"relation"`
+	Words    []string     `json:"words"`
+}
+
+// return the words linked to the senses of the query by a sense relation
+// (derivation, pertainym, participle, antonym...), grouped by relation type
+func (oe *OpenEnglishDictionary) RelatedWords(query string) ([]RelatedWords, error) {
+	finded, err := oe.findLexicalEntries(query)
+	if err != nil {
+		return nil, err
+	}
+	var senses []*Sense = make([]*Sense, 0)
+	for _, lexicalEntry := range finded {
+		senses = append(senses, lexicalEntry.Senses...)
+	}
+	return oe.groupRelatedWords(senses...), nil
+}
+
+func (oe *OpenEnglishDictionary) groupRelatedWords(senses ...*Sense) []RelatedWords {
+	var relationToWords map[RelationType][]string = make(map[RelationType][]string)
+	for _, sense := range senses {
+		for _, senseRelation := range sense.SenseRelations {
+			lexicalEntry, ok := oe.senseToLexicalEntry[senseRelation.Target]
+			if !ok {
+				continue
+			}
+			relationToWords[senseRelation.RelType] = appendWord(relationToWords[senseRelation.RelType], lexicalEntry.Lemma.WrittenForm)
+		}
+	}
+	return sortRelatedWords(relationToWords)
+}
+
+// the groups are ordered by relation type so the output is stable
+func sortRelatedWords(relationToWords map[RelationType][]string) []RelatedWords {
+	var related []RelatedWords = make([]RelatedWords, 0, len(relationToWords))
+	for relationType, words := range relationToWords {
+		related = append(related, RelatedWords{Relation: relationType, Words: words})
+	}
+	sort.Slice(related, func(i, j int) bool {
+		return related[i].Relation < related[j].Relation
+	})
+	return related
+}
+
+// append the word if it isn't already in the list
+func appendWord(words []string, word string) []string {
+	for _, w := range words {
+		if w == word {
+			return words
+		}
+	}
+	return append(words, word)
+}
+
+// depth used when the caller doesn't limit how far the relations are followed
+const DefaultRelationDepth = 5
+
+var meronymRelationTypes map[RelationType]bool = map[RelationType]bool{
+	RelationTypeMeroPart:      true,
+	RelationTypeMeroMember:    true,
+	RelationTypeMeroSubstance: true,
+	RelationTypeMeroPortion:   true,
+	RelationTypeMeroLocation:  true,
+	RelationTypeMeronym:       true,
+}
+
+var holonymRelationTypes map[RelationType]bool = map[RelationType]bool{
+	RelationTypeHoloPart:      true,
+	RelationTypeHoloMember:    true,
+	RelationTypeHoloSubstance: true,
+	RelationTypeHoloPortion:   true,
+	RelationTypeHoloLocation:  true,
+	RelationTypeHolonym:       true,
+}
+
+// node of a tree of synsets linked by synset relations, the roots are the
+// synsets of the searched word and don't have a Relation
+type RelationNode struct {
+	SynsetId string   `json:"synset_id"`
+	Words    []string `json:"words"`
+	// first definition of the synset
+	Definition string `json:"definition"`
+	// relation that links the parent to this node
+	Relation RelationType    `json:"relation,omitempty"`
+	Children []*RelationNode `json:"children"`
+}
+
+// return the parts (meronyms) of every sense of the query, following the
+// relations transitively until the depth, if depth <= 0 DefaultRelationDepth
+// is used
+func (oe *OpenEnglishDictionary) PartsOf(query string, depth int) ([]*RelationNode, error) {
+	return oe.followRelations(query, 0, meronymRelationTypes, depth)
+}
+
+// return the wholes (holonyms) of every sense of the query, following the
+// relations transitively until the depth, if depth <= 0 DefaultRelationDepth
+// is used
+func (oe *OpenEnglishDictionary) WholesOf(query string, depth int) ([]*RelationNode, error) {
+	return oe.followRelations(query, 0, holonymRelationTypes, depth)
+}
+
+// only the lexical entries with the part of speech are used, 0 means any
+func (oe *OpenEnglishDictionary) followRelations(query string, partOfSpeech PartOfSpeech, relationTypes map[RelationType]bool, depth int) ([]*RelationNode, error) {
+	finded, err := oe.findLexicalEntries(query)
+	if err != nil {
+		return nil, err
+	}
+	if depth <= 0 {
+		depth = DefaultRelationDepth
+	}
+	var roots []*RelationNode = make([]*RelationNode, 0)
+	for _, lexicalEntry := range finded {
+		if partOfSpeech != 0 && lexicalEntry.Lemma.PartOfSpeech != partOfSpeech {
+			continue
+		}
+		for _, sense := range lexicalEntry.Senses {
+			if sense.Synset == nil {
+				continue
+			}
+			// synsets already in the path, avoid walking in circles
+			visited := map[*Synset]bool{sense.Synset: true}
+			root := oe.newRelationNode(sense.Synset, RelationTypeNone)
+			oe.addRelationChildren(root, sense.Synset, relationTypes, depth, visited)
+			roots = append(roots, root)
+		}
+	}
+	return roots, nil
+}
+
+func (oe *OpenEnglishDictionary) addRelationChildren(node *RelationNode, synset *Synset, relationTypes map[RelationType]bool, depth int, visited map[*Synset]bool) {
+	if depth == 0 {
+		return
+	}
+	for _, synsetRelation := range synset.SynsetRelations {
+		target := synsetRelation.Target
+		if !relationTypes[synsetRelation.RelType] || target == nil || visited[target] {
+			continue
+		}
+		child := oe.newRelationNode(target, synsetRelation.RelType)
+		visited[target] = true
+		oe.addRelationChildren(child, target, relationTypes, depth-1, visited)
+		delete(visited, target)
+		node.Children = append(node.Children, child)
+	}
+}
+
+func (oe *OpenEnglishDictionary) newRelationNode(synset *Synset, relationType RelationType) *RelationNode {
+	node := &RelationNode{
+		SynsetId: synset.Id,
+		Words:    oe.synsetWords(synset),
+		Relation: relationType,
+		Children: make([]*RelationNode, 0),
+	}
+	if len(synset.Definitions) != 0 {
+		node.Definition = string(synset.Definitions[0])
+	}
+	return node
+}
+
+// return the lemmas of the lexical entries that have a sense on the synset
+func (oe *OpenEnglishDictionary) synsetWords(synset *Synset) []string {
+	var words []string = make([]string, 0)
+	for _, lexicalEntry := range oe.synsetToLexicalEntries[synset] {
+		words = appendWord(words, lexicalEntry.Lemma.WrittenForm)
+	}
+	return words
+}
+
+var verbRelationTypes map[RelationType]bool = map[RelationType]bool{
+	RelationTypeEntails:      true,
+	RelationTypeIsEntailedBy: true,
+	RelationTypeCauses:       true,
+	RelationTypeIsCausedBy:   true,
+}
+
+// return the entailment and causation chains of the verb senses of the query,
+// the senses of other parts of speech are left out
+func (oe *OpenEnglishDictionary) VerbRelations(query string, depth int) ([]*RelationNode, error) {
+	return oe.followRelations(query, PartOfSpeechVerb, verbRelationTypes, depth)
+}
+
+// return the words of the synsets linked to the synset by the relation type
+func (oe *OpenEnglishDictionary) relatedSynsetWords(synset *Synset, relationType RelationType) []string {
+	var words []string = make([]string, 0)
+	if synset == nil {
+		return words
+	}
+	for _, synsetRelation := range synset.SynsetRelations {
+		if synsetRelation.RelType != relationType || synsetRelation.Target == nil {
+			continue
+		}
+		for _, word := range oe.synsetWords(synsetRelation.Target) {
+			words = appendWord(words, word)
+		}
+	}
+	return words
+}
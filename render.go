@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+// write a lookup result in some output format
+type Renderer interface {
+	Render(w io.Writer, word *Word) error
+}
+
+// adapter to use ordinary functions as renderers
+type RendererFunc func(w io.Writer, word *Word) error
+
+func (rf RendererFunc) Render(w io.Writer, word *Word) error {
+	return rf(w, word)
+}
+
+var ErrInvalidFormat = errors.New("Invalid output format!")
+
+// renderers by format name, more formats can be added with RegisterRenderer
+var renderers map[string]Renderer = map[string]Renderer{
+	"json":     RendererFunc(renderJSON),
+	"yaml":     RendererFunc(renderYAML),
+	"markdown": RendererFunc(renderMarkdown),
+	"plain":    RendererFunc(renderPlain),
+	"html":     RendererFunc(renderHTML),
+}
+
+func RegisterRenderer(format string, renderer Renderer) {
+	renderers[format] = renderer
+}
+
+func NewRenderer(format string) (Renderer, error) {
+	renderer, ok := renderers[format]
+	if !ok {
+		return nil, ErrInvalidFormat
+	}
+	return renderer, nil
+}
+
+// names of the registered formats in alphabetical order
+func RendererFormats() []string {
+	var formats []string = make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// the JSON follows the json tags of Word, any change there is a change of the
+// schema used by the scripts
+func renderJSON(w io.Writer, word *Word) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(word)
+}
+
+// the YAML is generated from the JSON so both have the same schema, each
+// word is a document of its own
+func renderYAML(w io.Writer, word *Word) error {
+	data, err := json.Marshal(word)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeOrderedJSON(decoder)
+	if err != nil {
+		return err
+	}
+	builder := &strings.Builder{}
+	builder.WriteString("---\n")
+	writeYAML(builder, value, 0)
+	_, err = io.WriteString(w, builder.String())
+	return err
+}
+
+func renderMarkdown(w io.Writer, word *Word) error {
+	_, err := io.WriteString(w, markdownText(word)+"\n")
+	return err
+}
+
+func renderPlain(w io.Writer, word *Word) error {
+	for i, wordDefinition := range word.WordDefinitions {
+		if i != 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, plainDefinitionText(wordDefinition)+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var htmlTemplate *template.Template = template.Must(template.New("word").Parse(`<article class="word-def">
+{{- range .WordDefinitions}}
+<section>
+<h2>{{.WrittenForm}} <small>{{.PartOfSpeech}}</small>{{range .Pronunciations}} <span class="pronunciation">/{{.}}/</span>{{end}}</h2>
+{{- if .Forms}}
+<p class="forms">Forms: {{range $i, $form := .Forms}}{{if $i}}, {{end}}{{$form.WrittenForm}}{{end}}</p>
+{{- end}}
+<ol>
+{{- range .Definitions}}
+<li>
+{{- range .Definitions}}<p class="definition">{{.}}</p>{{end}}
+{{- if .Synonyms}}<p class="synonyms">Synonyms: {{range $i, $synonym := .Synonyms}}{{if $i}}, {{end}}{{$synonym}}{{end}}</p>{{end}}
+{{- if .UseExamples}}<ul class="examples">{{range .UseExamples}}<li>{{.Text}}</li>{{end}}</ul>{{end}}
+</li>
+{{- end}}
+</ol>
+</section>
+{{- end}}
+</article>
+`))
+
+func renderHTML(w io.Writer, word *Word) error {
+	return htmlTemplate.Execute(w, word)
+}
+
+// plain text of the definitions of a word, without any color tags
+func plainDefinitionText(wordDefinition WordDefinition) string {
+	builder := &strings.Builder{}
+	builder.WriteString(fmt.Sprintf("%s (%s)%s\n", wordDefinition.WrittenForm, wordDefinition.PartOfSpeech, pronunciationsToText(wordDefinition.Pronunciations)))
+	if len(wordDefinition.Forms) != 0 {
+		builder.WriteString(fmt.Sprintf("  Forms: %s\n", formsToText(wordDefinition.Forms)))
+	}
+	for i, def := range wordDefinition.Definitions {
+		builder.WriteString(fmt.Sprintf("\n  %d. %s\n", i+1, strings.Join(def.Definitions, "; ")))
+		if len(def.Synonyms) != 0 {
+			builder.WriteString(fmt.Sprintf("     Synonyms: %s\n", strings.Join(def.Synonyms, ", ")))
+		}
+		for _, frame := range def.Frames {
+			builder.WriteString(fmt.Sprintf("     > %s\n", frame))
+		}
+		for _, example := range def.UseExamples {
+			builder.WriteString(fmt.Sprintf("     \"%s\"\n", example.Text))
+		}
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// markdown with the definitions, examples and synonyms of each sense
+func markdownText(word *Word) string {
+	builder := &strings.Builder{}
+	for i, wordDefinition := range word.WordDefinitions {
+		if i != 0 {
+			builder.WriteString("\n---\n\n")
+		}
+		builder.WriteString(fmt.Sprintf("**%s** _%s_%s\n\n", wordDefinition.WrittenForm, wordDefinition.PartOfSpeech, pronunciationsToText(wordDefinition.Pronunciations)))
+		if len(wordDefinition.Forms) != 0 {
+			builder.WriteString(fmt.Sprintf("Forms: %s\n\n", formsToText(wordDefinition.Forms)))
+		}
+		for j, def := range wordDefinition.Definitions {
+			builder.WriteString(fmt.Sprintf("%d. %s\n", j+1, strings.Join(def.Definitions, "; ")))
+			for _, example := range def.UseExamples {
+				builder.WriteString(fmt.Sprintf("   - _%s_\n", example.Text))
+			}
+			if len(def.Synonyms) != 0 {
+				builder.WriteString(fmt.Sprintf("   - Synonyms: %s\n", strings.Join(def.Synonyms, ", ")))
+			}
+		}
+	}
+	return builder.String()
+}
+
+func formsToText(forms []WordForm) string {
+	texts := make([]string, len(forms))
+	for i, form := range forms {
+		texts[i] = form.WrittenForm
+		if len(form.Tags) != 0 {
+			texts[i] += fmt.Sprintf(" (%s)", tagsToText(form.Tags))
+		}
+	}
+	return strings.Join(texts, ", ")
+}
+
+// JSON object keeping the order of the keys
+type orderedObject struct {
+	keys   []string
+	values []any
+}
+
+// decode the next JSON value, the objects are decoded as orderedObject and
+// the arrays as []any
+func decodeOrderedJSON(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := &orderedObject{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrderedJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.keys = append(object.keys, key.(string))
+			object.values = append(object.values, value)
+		}
+		_, err = decoder.Token()
+		return object, err
+	case json.Delim('['):
+		var array []any = make([]any, 0)
+		for decoder.More() {
+			value, err := decodeOrderedJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = decoder.Token()
+		return array, err
+	}
+	return token, nil
+}
+
+func writeYAML(builder *strings.Builder, value any, indent int) {
+	padding := strings.Repeat(" ", indent)
+	switch v := value.(type) {
+	case *orderedObject:
+		for i, key := range v.keys {
+			builder.WriteString(padding + key + ":")
+			writeYAMLChild(builder, v.values[i], indent)
+		}
+	case []any:
+		for _, item := range v {
+			builder.WriteString(padding + "-")
+			if object, ok := item.(*orderedObject); ok && len(object.keys) != 0 {
+				// the first key goes in the line of the dash, the other ones
+				// are aligned with it
+				child := &strings.Builder{}
+				writeYAML(child, object, indent+2)
+				builder.WriteString(" " + strings.TrimLeft(child.String(), " "))
+				continue
+			}
+			writeYAMLChild(builder, item, indent)
+		}
+	default:
+		builder.WriteString(padding + yamlScalar(v) + "\n")
+	}
+}
+
+// write the value of a key or of an array item, the scalars and the empty
+// collections stay in the same line
+func writeYAMLChild(builder *strings.Builder, value any, indent int) {
+	switch v := value.(type) {
+	case *orderedObject:
+		if len(v.keys) == 0 {
+			builder.WriteString(" {}\n")
+			return
+		}
+		builder.WriteString("\n")
+		writeYAML(builder, v, indent+2)
+	case []any:
+		if len(v) == 0 {
+			builder.WriteString(" []\n")
+			return
+		}
+		builder.WriteString("\n")
+		writeYAML(builder, v, indent+2)
+	default:
+		builder.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+// the strings are written as JSON strings, that are valid YAML double quoted
+// scalars
+func yamlScalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		quoted, _ := json.Marshal(v)
+		return string(quoted)
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	}
+	return fmt.Sprint(value)
+}
+
+// the pronunciations between slashes, preceded by a space, empty without them
+func pronunciationsToText(pronunciations []string) string {
+	text := ""
+	for _, pronunciation := range pronunciations {
+		text += fmt.Sprintf(" /%s/", pronunciation)
+	}
+	return text
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SM-2 parameters, the ease factor of a new card and its lower bound
+const (
+	initialEaseFactor = 2.5
+	minimumEaseFactor = 1.3
+)
+
+// grades of the recall, from 0 (complete blackout) to 5 (perfect response),
+// the grades from 3 on are a successful recall
+const (
+	minimumGrade    = 0
+	passingGrade    = 3
+	maximumGrade    = 5
+	perfectionGrade = 4
+)
+
+// scheduling of a word, the interval is in days
+type ReviewCard struct {
+	Word         string    `json:"word"`
+	EaseFactor   float64   `json:"ease_factor"`
+	Interval     int       `json:"interval"`
+	Repetitions  int       `json:"repetitions"`
+	Due          time.Time `json:"due"`
+	LastReviewed time.Time `json:"last_reviewed"`
+}
+
+func NewReviewCard(word string) *ReviewCard {
+	return &ReviewCard{Word: word, EaseFactor: initialEaseFactor}
+}
+
+// schedule the next review of the card by the SM-2 algorithm, a failed
+// recall starts the repetitions again and keeps the ease factor
+func (rc *ReviewCard) Grade(grade int, now time.Time) {
+	grade = min(max(grade, minimumGrade), maximumGrade)
+	if grade >= passingGrade {
+		switch rc.Repetitions {
+		case 0:
+			rc.Interval = 1
+		case 1:
+			rc.Interval = 6
+		default:
+			rc.Interval = int(math.Round(float64(rc.Interval) * rc.EaseFactor))
+		}
+		rc.Repetitions++
+		distance := float64(maximumGrade - grade)
+		rc.EaseFactor = max(rc.EaseFactor+0.1-distance*(0.08+distance*0.02), minimumEaseFactor)
+	} else {
+		rc.Repetitions = 0
+		rc.Interval = 1
+	}
+	rc.LastReviewed = now
+	rc.Due = now.AddDate(0, 0, rc.Interval)
+}
+
+// the cards of the words reviewed, shared by all the lists
+type ReviewState struct {
+	path  string
+	Cards map[string]*ReviewCard `json:"cards"`
+}
+
+func reviewStatePath() string {
+	return filepath.Join(dataDir(), "review.json")
+}
+
+// load the review state from the file, a missing file gives no cards
+func LoadReviewState(path string) (*ReviewState, error) {
+	rs := &ReviewState{path: path, Cards: make(map[string]*ReviewCard)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return rs, nil
+	}
+	if err != nil {
+		return rs, err
+	}
+	if err := json.Unmarshal(data, rs); err != nil {
+		return rs, err
+	}
+	if rs.Cards == nil {
+		rs.Cards = make(map[string]*ReviewCard)
+	}
+	return rs, nil
+}
+
+func (rs *ReviewState) Save() error {
+	if rs.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(rs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(rs.path, append(data, '\n'))
+}
+
+// return the card of the word, a new one if the word was never reviewed
+func (rs *ReviewState) Card(word string) *ReviewCard {
+	card, ok := rs.Cards[word]
+	if !ok {
+		card = NewReviewCard(word)
+		rs.Cards[word] = card
+	}
+	return card
+}
+
+// the words of the list to review now, the never reviewed ones and the ones
+// with the review due, in the order of the list
+func (rs *ReviewState) DueWords(words []string, now time.Time) []string {
+	var due []string = make([]string, 0)
+	for _, word := range words {
+		card, ok := rs.Cards[word]
+		if !ok || !card.Due.After(now) {
+			due = append(due, word)
+		}
+	}
+	return due
+}
+
+// review session of a list, the words recalled with a grade below
+// perfectionGrade are asked again at the end of the session, as SM-2
+// recommends, but only the first grade of each word changes its schedule
+type ReviewSession struct {
+	state  *ReviewState
+	queue  []string
+	graded map[string]bool
+	// number of words graded for the first time in the session
+	Reviewed int
+}
+
+func NewReviewSession(state *ReviewState, words []string, now time.Time) *ReviewSession {
+	return &ReviewSession{
+		state:  state,
+		queue:  state.DueWords(words, now),
+		graded: make(map[string]bool),
+	}
+}
+
+// the word being asked, false when the session is over
+func (rs *ReviewSession) Current() (string, bool) {
+	if len(rs.queue) == 0 {
+		return "", false
+	}
+	return rs.queue[0], true
+}
+
+func (rs *ReviewSession) Remaining() int {
+	return len(rs.queue)
+}
+
+// grade the current word and move to the next one, the state is saved after
+// each first grade
+func (rs *ReviewSession) Grade(grade int, now time.Time) error {
+	word, ok := rs.Current()
+	if !ok {
+		return nil
+	}
+	rs.queue = rs.queue[1:]
+	if grade < perfectionGrade {
+		rs.queue = append(rs.queue, word)
+	}
+	if rs.graded[word] {
+		return nil
+	}
+	rs.graded[word] = true
+	rs.Reviewed++
+	rs.state.Card(word).Grade(grade, now)
+	return rs.state.Save()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReviewCardGrade(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		grades      []int
+		interval    int
+		repetitions int
+		easeFactor  float64
+	}{
+		{"first recall", []int{5}, 1, 1, 2.6},
+		{"second recall", []int{5, 5}, 6, 2, 2.7},
+		// the interval is multiplied by the ease factor before the update
+		{"third recall", []int{5, 5, 5}, 16, 3, 2.8},
+		{"hard recall", []int{3}, 1, 1, 2.36},
+		{"failed recall keeps the ease factor", []int{5, 5, 1}, 1, 0, 2.7},
+		{"ease factor lower bound", []int{3, 3, 3, 3, 3, 3, 3, 3, 3, 3}, 425, 10, minimumEaseFactor},
+		{"grade above the maximum", []int{9}, 1, 1, 2.6},
+	}
+	for _, test := range tests {
+		card := NewReviewCard("mouse")
+		for _, grade := range test.grades {
+			card.Grade(grade, now)
+		}
+		if card.Interval != test.interval {
+			t.Errorf("%s: got interval %d, want %d", test.name, card.Interval, test.interval)
+		}
+		if card.Repetitions != test.repetitions {
+			t.Errorf("%s: got %d repetitions, want %d", test.name, card.Repetitions, test.repetitions)
+		}
+		if math.Abs(card.EaseFactor-test.easeFactor) > 1e-9 {
+			t.Errorf("%s: got ease factor %f, want %f", test.name, card.EaseFactor, test.easeFactor)
+		}
+		if want := now.AddDate(0, 0, card.Interval); !card.Due.Equal(want) || !card.LastReviewed.Equal(now) {
+			t.Errorf("%s: got due %s, want %s", test.name, card.Due, want)
+		}
+	}
+}
+
+func TestReviewSession(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	state := &ReviewState{Cards: make(map[string]*ReviewCard)}
+	// a word not due yet isn't asked
+	state.Card("car").Grade(5, now)
+	session := NewReviewSession(state, []string{"mouse", "car", "wheel"}, now)
+
+	var asked []string = make([]string, 0)
+	grades := map[string]int{"mouse": 3, "wheel": 5}
+	for {
+		word, ok := session.Current()
+		if !ok {
+			break
+		}
+		asked = append(asked, word)
+		grade := grades[word]
+		// the second time mouse is recalled perfectly
+		grades[word] = 5
+		if err := session.Grade(grade, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// mouse was graded below perfectionGrade so it's asked again at the end
+	if !reflect.DeepEqual(asked, []string{"mouse", "wheel", "mouse"}) {
+		t.Errorf("got words %v, want [mouse wheel mouse]", asked)
+	}
+	if session.Reviewed != 2 {
+		t.Errorf("got %d words reviewed, want 2", session.Reviewed)
+	}
+	// only the first grade changes the schedule
+	if card := state.Cards["mouse"]; card.Repetitions != 1 || math.Abs(card.EaseFactor-2.36) > 1e-9 {
+		t.Errorf("got %d repetitions and ease factor %f, want 1 and 2.36", card.Repetitions, card.EaseFactor)
+	}
+}
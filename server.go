@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// number of suggestions returned when the request doesn't set the limit
+const defaultSuggestLimit = 10
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// return the handler of the JSON API:
+//
+//	GET /define/{word}?pos=v     the Word found by Search, only the parts
+//	                             of speech of pos when it is given
+//	GET /suggest?q=prefix&limit= words starting with the prefix
+//	GET /related?word=word       the RelatedWords of the word
+//	GET /synset/{id}             the SynsetInfo of the synset
+func newAPIHandler(dict Dictionary) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /define/{word}", func(w http.ResponseWriter, r *http.Request) {
+		query := r.PathValue("word")
+		if pos := r.URL.Query().Get("pos"); pos != "" {
+			partsOfSpeech, err := ParsePartsOfSpeech(pos)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+				return
+			}
+			query = withPartOfSpeechFilter(query, partsOfSpeech)
+		}
+		word, err := dict.Search(query)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, word)
+	})
+	mux.HandleFunc("GET /suggest", func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultSuggestLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "Invalid limit!"})
+				return
+			}
+			limit = parsed
+		}
+		prefix := r.URL.Query().Get("q")
+		if prefix == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "Missing the q parameter!"})
+			return
+		}
+		writeJSON(w, http.StatusOK, dict.Suggest(prefix, limit))
+	})
+	mux.HandleFunc("GET /related", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("word")
+		if query == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "Missing the word parameter!"})
+			return
+		}
+		related, err := dict.RelatedWords(query)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, related)
+	})
+	mux.HandleFunc("GET /synset/{id}", func(w http.ResponseWriter, r *http.Request) {
+		synset, err := dict.Synset(r.PathValue("id"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, synset)
+	})
+	return mux
+}
+
+// the not found errors of the dictionary are answered with 404
+func writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrWordNotFound) || errors.Is(err, ErrSynsetNotFound) {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// serve the API until the context is done, then wait for the requests in
+// progress to finish before returning
+func serveAPI(ctx context.Context, addr string, dict Dictionary) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           newAPIHandler(dict),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// dictionary with the nouns "dog" and "cat", linked by a similar relation
+func newTestDictionary() *OpenEnglishDictionary {
+	newEntry := func(word string, synsetId string) (*LexicalEntry, *Sense, *Synset) {
+		synset := NewSynset()
+		synset.Id = synsetId
+		synset.PartOfSpeech = PartOfSpeechNoun
+		synset.Definitions = append(synset.Definitions, Definition("a domestic "+word))
+		sense := NewSense()
+		sense.Id = synsetId + "-sense"
+		sense.Synset = synset
+		lexicalEntry := NewLexicalEntry()
+		lexicalEntry.Id = "test-" + word + "-n"
+		lexicalEntry.Lemma = NewLemma()
+		lexicalEntry.Lemma.WrittenForm = word
+		lexicalEntry.Lemma.PartOfSpeech = PartOfSpeechNoun
+		lexicalEntry.Senses = append(lexicalEntry.Senses, sense)
+		return lexicalEntry, sense, synset
+	}
+	dog, dogSense, dogSynset := newEntry("dog", "test-dog-n")
+	cat, catSense, catSynset := newEntry("cat", "test-cat-n")
+	dogSense.SenseRelations = append(dogSense.SenseRelations, NewSenseRelation(catSense, "similar"))
+
+	lexicon := newLexicon()
+	lexicon.LexicalEntrys = append(lexicon.LexicalEntrys, dog, cat)
+	lexicon.Synsets = append(lexicon.Synsets, dogSynset, catSynset)
+	lexicalResource := newLexicalResource()
+	lexicalResource.Lexicons = append(lexicalResource.Lexicons, lexicon)
+	return NewOpenEnglishDictionary(lexicalResource)
+}
+
+func TestAPIHandler(t *testing.T) {
+	handler := newAPIHandler(newTestDictionary())
+	tests := []struct {
+		name   string
+		target string
+		status int
+	}{
+		{"define", "/define/dog", http.StatusOK},
+		{"define with pos", "/define/dog?pos=n", http.StatusOK},
+		{"define not found", "/define/unicorn", http.StatusNotFound},
+		{"define other pos", "/define/dog?pos=v", http.StatusNotFound},
+		{"define invalid pos", "/define/dog?pos=bogus", http.StatusBadRequest},
+		{"suggest", "/suggest?q=d", http.StatusOK},
+		{"suggest without q", "/suggest", http.StatusBadRequest},
+		{"suggest invalid limit", "/suggest?q=d&limit=many", http.StatusBadRequest},
+		{"suggest zero limit", "/suggest?q=d&limit=0", http.StatusBadRequest},
+		{"related", "/related?word=dog", http.StatusOK},
+		{"related without word", "/related", http.StatusBadRequest},
+		{"related not found", "/related?word=unicorn", http.StatusNotFound},
+		{"synset", "/synset/test-dog-n", http.StatusOK},
+		{"synset not found", "/synset/test-unicorn-n", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.target, nil))
+			if recorder.Code != test.status {
+				t.Errorf("GET %s: got status %d, want %d", test.target, recorder.Code, test.status)
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("GET %s: got Content-Type %q, want application/json", test.target, contentType)
+			}
+		})
+	}
+}
+
+func TestAPIHandlerBodies(t *testing.T) {
+	server := httptest.NewServer(newAPIHandler(newTestDictionary()))
+	defer server.Close()
+
+	get := func(target string, value any) {
+		t.Helper()
+		response, err := http.Get(server.URL + target)
+		if err != nil {
+			t.Fatalf("GET %s: %s", target, err)
+		}
+		defer response.Body.Close()
+		if err := json.NewDecoder(response.Body).Decode(value); err != nil {
+			t.Fatalf("GET %s: %s", target, err)
+		}
+	}
+
+	var word struct {
+		WordDefinitions []struct {
+			WrittenForm  string `json:"written_form"`
+			PartOfSpeech string `json:"part_of_speech"`
+		} `json:"word_definitions"`
+	}
+	get("/define/dog", &word)
+	if len(word.WordDefinitions) != 1 || word.WordDefinitions[0].WrittenForm != "dog" || word.WordDefinitions[0].PartOfSpeech != "Noun" {
+		t.Errorf("GET /define/dog: got %+v", word.WordDefinitions)
+	}
+
+	var suggestions []string
+	get("/suggest?q=c&limit=5", &suggestions)
+	if len(suggestions) != 1 || suggestions[0] != "cat" {
+		t.Errorf("GET /suggest?q=c: got %v, want [cat]", suggestions)
+	}
+
+	var related []struct {
+		Relation string   `json:"relation"`
+		Words    []string `json:"words"`
+	}
+	get("/related?word=dog", &related)
+	if len(related) != 1 || related[0].Relation != "similar" || len(related[0].Words) != 1 || related[0].Words[0] != "cat" {
+		t.Errorf("GET /related?word=dog: got %+v", related)
+	}
+
+	var notFound errorResponse
+	get("/synset/test-unicorn-n", &notFound)
+	if notFound.Error == "" {
+		t.Errorf("GET /synset/test-unicorn-n: the error message is empty")
+	}
+}
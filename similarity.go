@@ -0,0 +1,392 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"slices"
+)
+
+type SimilarityMetric int8
+
+const (
+	// inverse of the shortest path between the synsets in the hypernym hierarchy
+	SimilarityMetricPath = SimilarityMetric(iota)
+	// Wu-Palmer, based on the depth of the synsets and of their lowest common hypernym
+	SimilarityMetricWuPalmer
+	// Leacock-Chodorow, based on the shortest path and the depth of the taxonomy
+	SimilarityMetricLeacockChodorow
+)
+
+var similarityMetricNames map[string]SimilarityMetric = map[string]SimilarityMetric{
+	"path": SimilarityMetricPath,
+	"wup":  SimilarityMetricWuPalmer,
+	"lch":  SimilarityMetricLeacockChodorow,
+}
+
+func ParseSimilarityMetric(name string) (SimilarityMetric, error) {
+	metric, ok := similarityMetricNames[name]
+	if !ok {
+		return 0, errors.New("Invalid similarity metric, use path, wup or lch!")
+	}
+	return metric, nil
+}
+
+func (sm SimilarityMetric) String() string {
+	for name, metric := range similarityMetricNames {
+		if metric == sm {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+var hypernymRelationTypes map[RelationType]bool = map[RelationType]bool{
+	RelationTypeHypernym:         true,
+	RelationTypeInstanceHypernym: true,
+}
+
+// compute the depth of every synset in the hypernym hierarchy, the roots have
+// depth 1, and the maximum depth of the taxonomy of each part of speech
+func (oe *OpenEnglishDictionary) computeTaxonomyDepths() {
+	oe.synsetDepth = make(map[*Synset]int, len(oe.synsetToLexicalEntries))
+	oe.maxTaxonomyDepth = make(map[PartOfSpeech]int, 2)
+	for synset := range oe.synsetToLexicalEntries {
+		depth := oe.taxonomyDepth(synset, make(map[*Synset]bool))
+		partOfSpeech := oe.synsetPartOfSpeech(synset)
+		if depth > oe.maxTaxonomyDepth[partOfSpeech] {
+			oe.maxTaxonomyDepth[partOfSpeech] = depth
+		}
+	}
+}
+
+// shortest distance to a root plus one, memoized in synsetDepth
+func (oe *OpenEnglishDictionary) taxonomyDepth(synset *Synset, visiting map[*Synset]bool) int {
+	if depth, ok := oe.synsetDepth[synset]; ok {
+		return depth
+	}
+	visiting[synset] = true
+	depth := 1
+	for _, hypernym := range hypernyms(synset) {
+		if visiting[hypernym] {
+			continue
+		}
+		hypernymDepth := oe.taxonomyDepth(hypernym, visiting)
+		if depth == 1 || hypernymDepth+1 < depth {
+			depth = hypernymDepth + 1
+		}
+	}
+	delete(visiting, synset)
+	oe.synsetDepth[synset] = depth
+	return depth
+}
+
+func hypernyms(synset *Synset) []*Synset {
+	var hypernyms []*Synset = make([]*Synset, 0, 1)
+	for _, synsetRelation := range synset.SynsetRelations {
+		if hypernymRelationTypes[synsetRelation.RelType] && synsetRelation.Target != nil {
+			hypernyms = append(hypernyms, synsetRelation.Target)
+		}
+	}
+	return hypernyms
+}
+
+// return every hypernym of the synset, including itself, with the shortest
+// distance to it
+func hypernymDistances(synset *Synset) map[*Synset]int {
+	var distances map[*Synset]int = map[*Synset]int{synset: 0}
+	var queue []*Synset = []*Synset{synset}
+	for len(queue) != 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, hypernym := range hypernyms(next) {
+			if _, ok := distances[hypernym]; !ok {
+				distances[hypernym] = distances[next] + 1
+				queue = append(queue, hypernym)
+			}
+		}
+	}
+	return distances
+}
+
+// the part of speech of the synset, the one of its lemmas when the synset
+// doesn't have it, the adjective satellites are part of the adjectives
+func (oe *OpenEnglishDictionary) synsetPartOfSpeech(synset *Synset) PartOfSpeech {
+	partOfSpeech := synset.PartOfSpeech
+	if partOfSpeech == 0 {
+		lexicalEntries := oe.synsetToLexicalEntries[synset]
+		if len(lexicalEntries) == 0 {
+			return 0
+		}
+		partOfSpeech = lexicalEntries[0].Lemma.PartOfSpeech
+	}
+	if partOfSpeech == PartOfSpeechAdjectiveSatellite {
+		return PartOfSpeechAdjective
+	}
+	return partOfSpeech
+}
+
+// return the lowest common hypernym of the synsets, the deepest one shared by
+// both, and the distances from each synset to it, their sum is the length of
+// the shortest path between the synsets through it
+func (oe *OpenEnglishDictionary) lowestCommonHypernym(a *Synset, b *Synset) (*Synset, int, int, bool) {
+	distancesA := hypernymDistances(a)
+	distancesB := hypernymDistances(b)
+	var lowest *Synset
+	var lowestA, lowestB int
+	for hypernym, distanceA := range distancesA {
+		distanceB, ok := distancesB[hypernym]
+		if !ok {
+			continue
+		}
+		depth := oe.synsetDepth[hypernym]
+		length := distanceA + distanceB
+		if lowest == nil || depth > oe.synsetDepth[lowest] || (depth == oe.synsetDepth[lowest] && length < lowestA+lowestB) {
+			lowest = hypernym
+			lowestA, lowestB = distanceA, distanceB
+		}
+	}
+	return lowest, lowestA, lowestB, lowest != nil
+}
+
+// shortest path length between the synsets through any common hypernym
+func shortestHypernymPath(a *Synset, b *Synset) (int, bool) {
+	distancesA := hypernymDistances(a)
+	distancesB := hypernymDistances(b)
+	shortest := -1
+	for hypernym, distanceA := range distancesA {
+		distanceB, ok := distancesB[hypernym]
+		if ok && (shortest == -1 || distanceA+distanceB < shortest) {
+			shortest = distanceA + distanceB
+		}
+	}
+	return shortest, shortest != -1
+}
+
+func (oe *OpenEnglishDictionary) synsetSimilarity(a *Synset, b *Synset, metric SimilarityMetric) (float64, bool) {
+	switch metric {
+	case SimilarityMetricPath:
+		length, ok := shortestHypernymPath(a, b)
+		if !ok {
+			return 0, false
+		}
+		return 1 / float64(length+1), true
+	case SimilarityMetricWuPalmer:
+		lowest, distanceA, distanceB, ok := oe.lowestCommonHypernym(a, b)
+		if !ok {
+			return 0, false
+		}
+		depth := float64(oe.synsetDepth[lowest])
+		return 2 * depth / (float64(distanceA+distanceB) + 2*depth), true
+	case SimilarityMetricLeacockChodorow:
+		partOfSpeech := oe.synsetPartOfSpeech(a)
+		if partOfSpeech != oe.synsetPartOfSpeech(b) {
+			return 0, false
+		}
+		length, ok := shortestHypernymPath(a, b)
+		if !ok {
+			return 0, false
+		}
+		return -math.Log(float64(length+1) / float64(2*oe.maxTaxonomyDepth[partOfSpeech])), true
+	}
+	return 0, false
+}
+
+// return the highest similarity between the senses of the two words
+func (oe *OpenEnglishDictionary) Similarity(a string, b string, metric SimilarityMetric) (float64, error) {
+	synsetsA, err := oe.findSynsets(a)
+	if err != nil {
+		return 0, err
+	}
+	synsetsB, err := oe.findSynsets(b)
+	if err != nil {
+		return 0, err
+	}
+	var found bool = false
+	var best float64
+	for _, synsetA := range synsetsA {
+		for _, synsetB := range synsetsB {
+			similarity, ok := oe.synsetSimilarity(synsetA, synsetB, metric)
+			if ok && (!found || similarity > best) {
+				found = true
+				best = similarity
+			}
+		}
+	}
+	if !found {
+		return 0, errors.New("The words don't share a hypernym!")
+	}
+	return best, nil
+}
+
+// return the synsets of every sense of the query
+func (oe *OpenEnglishDictionary) findSynsets(query string) ([]*Synset, error) {
+	finded, err := oe.findLexicalEntries(query)
+	if err != nil {
+		return nil, err
+	}
+	var synsets []*Synset = make([]*Synset, 0)
+	for _, lexicalEntry := range finded {
+		for _, sense := range lexicalEntry.Senses {
+			if sense.Synset != nil {
+				synsets = append(synsets, sense.Synset)
+			}
+		}
+	}
+	return synsets, nil
+}
+
+// step of a path in the synset graph
+type PathStep struct {
+	SynsetId string   `json:"synset_id"`
+	Words    []string `json:"words"`
+	// relation that links the previous step to this one, RelationTypeNone
+	// on the first step of the path
+	Relation RelationType `json:"relation,omitempty"`
+}
+
+type CommonHypernym struct {
+	// the lowest common hypernym of the words
+	Hypernym PathStep `json:"hypernym"`
+	// hypernym chains from a sense of each word up to the common hypernym,
+	// both end with the common hypernym
+	ChainA []PathStep `json:"chain_a"`
+	ChainB []PathStep `json:"chain_b"`
+}
+
+// return the deepest hypernym shared by a sense of each word, preferring the
+// shortest path between the senses when there is more than one
+func (oe *OpenEnglishDictionary) LowestCommonHypernym(a string, b string) (*CommonHypernym, error) {
+	synsetsA, err := oe.findSynsets(a)
+	if err != nil {
+		return nil, err
+	}
+	synsetsB, err := oe.findSynsets(b)
+	if err != nil {
+		return nil, err
+	}
+	var lowest, bestA, bestB *Synset
+	var bestLength int
+	for _, synsetA := range synsetsA {
+		for _, synsetB := range synsetsB {
+			hypernym, distanceA, distanceB, ok := oe.lowestCommonHypernym(synsetA, synsetB)
+			if !ok {
+				continue
+			}
+			length := distanceA + distanceB
+			if lowest == nil || oe.synsetDepth[hypernym] > oe.synsetDepth[lowest] || (oe.synsetDepth[hypernym] == oe.synsetDepth[lowest] && length < bestLength) {
+				lowest, bestA, bestB, bestLength = hypernym, synsetA, synsetB, length
+			}
+		}
+	}
+	if lowest == nil {
+		return nil, errors.New("The words don't share a hypernym!")
+	}
+	return &CommonHypernym{
+		Hypernym: oe.newPathStep(lowest, RelationTypeNone),
+		ChainA:   oe.hypernymChain(bestA, lowest),
+		ChainB:   oe.hypernymChain(bestB, lowest),
+	}, nil
+}
+
+// return the shortest chain of hypernym relations from the synset to one of
+// its hypernyms
+func (oe *OpenEnglishDictionary) hypernymChain(from *Synset, to *Synset) []PathStep {
+	var parents map[*Synset]pathLink = make(map[*Synset]pathLink)
+	var queue []*Synset = []*Synset{from}
+	for len(queue) != 0 && queue[0] != to {
+		next := queue[0]
+		queue = queue[1:]
+		for _, synsetRelation := range next.SynsetRelations {
+			target := synsetRelation.Target
+			if !hypernymRelationTypes[synsetRelation.RelType] || target == nil || target == from {
+				continue
+			}
+			if _, ok := parents[target]; ok {
+				continue
+			}
+			parents[target] = pathLink{previous: next, relation: synsetRelation.RelType}
+			queue = append(queue, target)
+		}
+	}
+	return oe.buildPath(from, to, parents)
+}
+
+// return the shortest path between a sense of each word following any synset
+// relation
+func (oe *OpenEnglishDictionary) ShortestPath(a string, b string) ([]PathStep, error) {
+	synsetsA, err := oe.findSynsets(a)
+	if err != nil {
+		return nil, err
+	}
+	synsetsB, err := oe.findSynsets(b)
+	if err != nil {
+		return nil, err
+	}
+	var targets map[*Synset]bool = make(map[*Synset]bool, len(synsetsB))
+	for _, synset := range synsetsB {
+		targets[synset] = true
+	}
+
+	// breadth first search starting from every sense of the first word, the
+	// origin of each synset is kept to build the path at the end
+	var parents map[*Synset]pathLink = make(map[*Synset]pathLink)
+	var origins map[*Synset]*Synset = make(map[*Synset]*Synset)
+	var queue []*Synset = make([]*Synset, 0, len(synsetsA))
+	for _, synset := range synsetsA {
+		if _, ok := origins[synset]; !ok {
+			origins[synset] = synset
+			queue = append(queue, synset)
+		}
+	}
+	for len(queue) != 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if targets[next] {
+			return oe.buildPath(origins[next], next, parents), nil
+		}
+		for _, synsetRelation := range next.SynsetRelations {
+			target := synsetRelation.Target
+			if target == nil {
+				continue
+			}
+			if _, ok := origins[target]; ok {
+				continue
+			}
+			origins[target] = origins[next]
+			parents[target] = pathLink{previous: next, relation: synsetRelation.RelType}
+			queue = append(queue, target)
+		}
+	}
+	return nil, errors.New("There's no path between the words!")
+}
+
+// the synset from where a synset was reached in a search and by which relation
+type pathLink struct {
+	previous *Synset
+	relation RelationType
+}
+
+// walk the parents from the end of the path back to its start
+func (oe *OpenEnglishDictionary) buildPath(from *Synset, to *Synset, parents map[*Synset]pathLink) []PathStep {
+	var path []PathStep = make([]PathStep, 0)
+	current := to
+	for current != from {
+		link, ok := parents[current]
+		if !ok {
+			return nil
+		}
+		path = append(path, oe.newPathStep(current, link.relation))
+		current = link.previous
+	}
+	path = append(path, oe.newPathStep(from, RelationTypeNone))
+	slices.Reverse(path)
+	return path
+}
+
+func (oe *OpenEnglishDictionary) newPathStep(synset *Synset, relationType RelationType) PathStep {
+	return PathStep{
+		SynsetId: synset.Id,
+		Words:    oe.synsetWords(synset),
+		Relation: relationType,
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// dictionary of nouns with the taxonomy
+//
+//	entity
+//	├── animal
+//	│   └── mammal
+//	│       ├── dog
+//	│       └── cat
+//	└── plant
+//	    └── tree
+func newTaxonomyDictionary() *OpenEnglishDictionary {
+	lexicon := newLexicon()
+	synsets := make(map[string]*Synset)
+	add := func(word string, hypernym string) {
+		synset := NewSynset()
+		synset.Id = "test-" + word + "-n"
+		synset.PartOfSpeech = PartOfSpeechNoun
+		if hypernym != "" {
+			synset.SynsetRelations = append(synset.SynsetRelations, NewSynsetRelation(synsets[hypernym], "hypernym"))
+			// the data lists the inverse relation as well
+			synsets[hypernym].SynsetRelations = append(synsets[hypernym].SynsetRelations, NewSynsetRelation(synset, "hyponym"))
+		}
+		synsets[word] = synset
+		sense := NewSense()
+		sense.Synset = synset
+		lexicalEntry := NewLexicalEntry()
+		lexicalEntry.Lemma = NewLemma()
+		lexicalEntry.Lemma.WrittenForm = word
+		lexicalEntry.Lemma.PartOfSpeech = PartOfSpeechNoun
+		lexicalEntry.Senses = append(lexicalEntry.Senses, sense)
+		lexicon.LexicalEntrys = append(lexicon.LexicalEntrys, lexicalEntry)
+		lexicon.Synsets = append(lexicon.Synsets, synset)
+	}
+	add("entity", "")
+	add("animal", "entity")
+	add("mammal", "animal")
+	add("dog", "mammal")
+	add("cat", "mammal")
+	add("plant", "entity")
+	add("tree", "plant")
+	lexicalResource := newLexicalResource()
+	lexicalResource.Lexicons = append(lexicalResource.Lexicons, lexicon)
+	return NewOpenEnglishDictionary(lexicalResource)
+}
+
+func TestSimilarity(t *testing.T) {
+	dict := newTaxonomyDictionary()
+	tests := []struct {
+		a, b   string
+		metric SimilarityMetric
+		want   float64
+	}{
+		// path of length 2 through mammal
+		{"dog", "cat", SimilarityMetricPath, 1.0 / 3},
+		// mammal has depth 3, dog and cat are one step below it
+		{"dog", "cat", SimilarityMetricWuPalmer, 2 * 3.0 / (1 + 1 + 2*3)},
+		// the taxonomy has depth 4
+		{"dog", "cat", SimilarityMetricLeacockChodorow, -math.Log(3.0 / (2 * 4))},
+		// path of length 5 through entity
+		{"dog", "tree", SimilarityMetricPath, 1.0 / 6},
+		{"dog", "tree", SimilarityMetricWuPalmer, 2 * 1.0 / (3 + 2 + 2*1)},
+		{"dog", "tree", SimilarityMetricLeacockChodorow, -math.Log(6.0 / (2 * 4))},
+		{"dog", "dog", SimilarityMetricPath, 1},
+		{"dog", "dog", SimilarityMetricWuPalmer, 1},
+	}
+	for _, test := range tests {
+		got, err := dict.Similarity(test.a, test.b, test.metric)
+		if err != nil {
+			t.Errorf("Similarity(%q, %q, %s): %s", test.a, test.b, test.metric, err)
+			continue
+		}
+		if math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("Similarity(%q, %q, %s): got %f, want %f", test.a, test.b, test.metric, got, test.want)
+		}
+	}
+}
+
+func TestLowestCommonHypernym(t *testing.T) {
+	dict := newTaxonomyDictionary()
+	common, err := dict.LowestCommonHypernym("dog", "tree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(common.Hypernym.Words, []string{"entity"}) {
+		t.Errorf("got hypernym %v, want [entity]", common.Hypernym.Words)
+	}
+	if len(common.ChainA) != 4 || len(common.ChainB) != 3 {
+		t.Errorf("got chains of %d and %d steps, want 4 and 3", len(common.ChainA), len(common.ChainB))
+	}
+	if common.ChainA[0].Relation != RelationTypeNone || common.ChainA[1].Relation != RelationTypeHypernym {
+		t.Errorf("got relations %s and %s, want none and hypernym", common.ChainA[0].Relation, common.ChainA[1].Relation)
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	dict := newTaxonomyDictionary()
+	path, err := dict.ShortestPath("dog", "cat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var words []string = make([]string, len(path))
+	for i, step := range path {
+		words[i] = step.Words[0]
+	}
+	if !reflect.DeepEqual(words, []string{"dog", "mammal", "cat"}) {
+		t.Errorf("got path %v, want [dog mammal cat]", words)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var ErrInvalidTheme = errors.New("Invalid theme, use default, no-color or high-contrast!")
+
+// tview style tags ("foreground:background:attributes") of each kind of text
+// of the TUI, an empty style leaves the text as is
+type Theme struct {
+	Headword      string
+	PartOfSpeech  string
+	Pronunciation string
+	// forms, synonyms and related words
+	Word    string
+	Heading string
+	// definitions, sense numbers and prompts
+	Definition   string
+	Example      string
+	SenseExample string
+	Frame        string
+	// relation labels of the trees
+	Relation string
+	// tags, interlingual definitions and hints
+	Note    string
+	Success string
+	// draw the borders and selections without colors
+	Monochrome bool
+}
+
+var themes map[string]Theme = map[string]Theme{
+	"default": {
+		Headword:      "blue::b",
+		PartOfSpeech:  "green",
+		Pronunciation: "purple",
+		Word:          "blue",
+		Heading:       "red::u",
+		Definition:    "yellow",
+		Example:       "cyan",
+		SenseExample:  "cyan::b",
+		Frame:         "purple",
+		Relation:      "green",
+		Note:          "gray::i",
+		Success:       "green::b",
+	},
+	// only attributes, as asked by NO_COLOR
+	"no-color": {
+		Headword:     "::b",
+		Heading:      "::u",
+		SenseExample: "::b",
+		Note:         "::i",
+		Success:      "::b",
+		Monochrome:   true,
+	},
+	"high-contrast": {
+		Headword:      "white::b",
+		PartOfSpeech:  "lime::b",
+		Pronunciation: "fuchsia",
+		Word:          "aqua::b",
+		Heading:       "yellow::bu",
+		Definition:    "white",
+		Example:       "aqua",
+		SenseExample:  "aqua::b",
+		Frame:         "fuchsia",
+		Relation:      "lime::b",
+		Note:          "silver::i",
+		Success:       "lime::b",
+	},
+}
+
+// theme of the TUI, set from the configuration
+var theme Theme = themes["default"]
+
+// the no-color theme is used when the NO_COLOR environment variable is set,
+// whatever the configuration says
+func noColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// wrap the text in the style, resetting the colors and attributes after it
+func paint(style string, text string) string {
+	if style == "" {
+		return text
+	}
+	return "[" + style + "]" + text + "[-:-:-]"
+}
+
+// set the tview styles of the borders and the default text for the theme
+func applyTviewTheme(t Theme) {
+	if !t.Monochrome {
+		return
+	}
+	tview.Styles = tview.Theme{
+		PrimitiveBackgroundColor:    tcell.ColorDefault,
+		ContrastBackgroundColor:     tcell.ColorDefault,
+		MoreContrastBackgroundColor: tcell.ColorDefault,
+		BorderColor:                 tcell.ColorDefault,
+		TitleColor:                  tcell.ColorDefault,
+		GraphicsColor:               tcell.ColorDefault,
+		PrimaryTextColor:            tcell.ColorDefault,
+		SecondaryTextColor:          tcell.ColorDefault,
+		TertiaryTextColor:           tcell.ColorDefault,
+		InverseTextColor:            tcell.ColorDefault,
+		ContrastSecondaryTextColor:  tcell.ColorDefault,
+	}
+}
+
+// the style of the selected items of the lists, reversed when there are no
+// colors to tell them apart
+func selectedStyle(t Theme) tcell.Style {
+	if t.Monochrome {
+		return tcell.StyleDefault.Reverse(true)
+	}
+	return tcell.StyleDefault.Foreground(tview.Styles.PrimitiveBackgroundColor).Background(tview.Styles.PrimaryTextColor)
+}
@@ -1,28 +1,191 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-func generateTextToShow(word *Word) string {
+type textOptions struct {
+	// in compact mode only the first definition of each sense is shown, verbose
+	// mode adds the remaining definitions, the interlingual one and the examples
+	verbose bool
+	// expand the related words section of each sense
+	showRelated bool
+	// mark the words of the definitions, examples and relations as regions,
+	// so they can be followed
+	links bool
+	// show only this sense, every sense when nil
+	sense *senseIndex
+	// key that expands the related words, shown in the hint
+	expandKey string
+	// entailment and causation chains of the verb senses by synset id
+	verbRelations map[string]*RelationNode
+}
+
+// position of a sense in a Word, the index of the word definition and the
+// index of the sense in its definitions
+type senseIndex struct {
+	definition int
+	sense      int
+}
+
+// terminal widths where the layout shows the sense list and the relations
+// tree, and also the history
+const (
+	mediumLayoutWidth = 100
+	wideLayoutWidth   = 140
+)
+
+// readable names for the relation types, the other ones are shown by the
+// relType name
+var relationTypeLabels map[RelationType]string = map[RelationType]string{
+	RelationTypeAntonym:       "Antonyms",
+	RelationTypeAlso:          "See also",
+	RelationTypeParticiple:    "Participle of",
+	RelationTypePertainym:     "Pertains to",
+	RelationTypeDerivation:    "Derivationally related",
+	RelationTypeSimilar:       "Similar to",
+	RelationTypeExemplifies:   "Exemplifies",
+	RelationTypeHoloPart:      "part of",
+	RelationTypeMeroPart:      "has part",
+	RelationTypeHoloMember:    "member of",
+	RelationTypeMeroMember:    "has member",
+	RelationTypeHoloSubstance: "substance of",
+	RelationTypeMeroSubstance: "made of",
+	RelationTypeHoloPortion:   "portion of",
+	RelationTypeMeroPortion:   "has portion",
+	RelationTypeHoloLocation:  "location of",
+	RelationTypeMeroLocation:  "has location",
+	RelationTypeHolonym:       "whole",
+	RelationTypeMeronym:       "part",
+	RelationTypeEntails:       "entails",
+	RelationTypeIsEntailedBy:  "entailed by",
+	RelationTypeCauses:        "causes",
+	RelationTypeIsCausedBy:    "caused by",
+}
+
+func relationTypeLabel(relationType RelationType) string {
+	label, ok := relationTypeLabels[relationType]
+	if !ok {
+		return strings.ReplaceAll(relationType.String(), "_", " ")
+	}
+	return label
+}
+
+func generateTextToShow(word *Word, options textOptions) string {
 	builderString := &strings.Builder{}
-	for _, wordDefinition := range word.WordDefinitions {
-        builderString.WriteString(fmt.Sprintf("[blue::b]%s[-::-]([green]%s[-]):", wordDefinition.WrittenForm, wordDefinition.PartOfSpeech))
+	// the regions are numbered in the order of the text, so Tab can follow it
+	regions := 0
+	link := func(text string) string {
+		if !options.links {
+			return text
+		}
+		regions++
+		return fmt.Sprintf(`["w%d"]%s[""]`, regions-1, text)
+	}
+	linkWords := func(text string) string {
+		if !options.links {
+			return text
+		}
+		linked := &strings.Builder{}
+		for _, token := range tokenize(text) {
+			if isWordToken(token) {
+				token = link(token)
+			}
+			linked.WriteString(token)
+		}
+		return linked.String()
+	}
+	linkList := func(words []string) string {
+		linked := make([]string, len(words))
+		for i, word := range words {
+			linked[i] = link(word)
+		}
+		return strings.Join(linked, ", ")
+	}
+	// one line per node, indented by its depth in the chain
+	var writeChain func(node *RelationNode, indent string)
+	writeChain = func(node *RelationNode, indent string) {
+		for _, child := range node.Children {
+			builderString.WriteString(fmt.Sprintf("%s%s → %s\n", indent, paint(theme.Relation, relationTypeLabel(child.Relation)), paint(theme.Word, linkList(child.Words))))
+			writeChain(child, indent+"  ")
+		}
+	}
+	for d, wordDefinition := range word.WordDefinitions {
+		if options.sense != nil && options.sense.definition != d {
+			continue
+		}
+        builderString.WriteString(fmt.Sprintf("%s(%s)", paint(theme.Headword, wordDefinition.WrittenForm), paint(theme.PartOfSpeech, wordDefinition.PartOfSpeech.String())))
+		if len(wordDefinition.Pronunciations) != 0 {
+			builderString.WriteString(paint(theme.Pronunciation, pronunciationsToText(wordDefinition.Pronunciations)))
+		}
+		if len(wordDefinition.Tags) != 0 {
+			builderString.WriteString(" " + paint(theme.Note, tagsToText(wordDefinition.Tags)))
+		}
+		builderString.WriteString(":")
+		if len(wordDefinition.Forms) != 0 {
+			forms := make([]string, len(wordDefinition.Forms))
+			for i, form := range wordDefinition.Forms {
+				forms[i] = paint(theme.Word, link(form.WrittenForm))
+				if len(form.Tags) != 0 {
+					forms[i] += " " + paint(theme.Note, "("+tagsToText(form.Tags)+")")
+				}
+			}
+			builderString.WriteString(fmt.Sprintf("\n%s: %s", paint(theme.Heading, "Forms"), strings.Join(forms, ", ")))
+		}
 		if len(wordDefinition.Definitions) == 0 {
 			builderString.WriteString("There's no definitions for this word!")
 		}
 		for i, def := range wordDefinition.Definitions {
-			builderString.WriteString(fmt.Sprintf("\n[yellow]%d: %s[yellow]\n", i+1, def.Definitions[0]))
+			if options.sense != nil && options.sense.sense != i {
+				continue
+			}
+			if len(def.Definitions) == 0 {
+				builderString.WriteString(fmt.Sprintf("\n%s%s\n", paint(theme.Definition, fmt.Sprintf("%d: ", i+1)), paint(theme.Note, "There's no definition for this sense!")))
+			} else {
+				builderString.WriteString(fmt.Sprintf("\n%s\n", paint(theme.Definition, fmt.Sprintf("%d: %s", i+1, linkWords(def.Definitions[0])))))
+			}
+			if chain, ok := options.verbRelations[def.SynsetId]; ok && len(chain.Children) != 0 {
+				writeChain(chain, " ")
+			}
+			if !options.verbose {
+				continue
+			}
+			for _, definition := range def.Definitions[min(1, len(def.Definitions)):] {
+				builderString.WriteString(fmt.Sprintf("   %s\n", paint(theme.Definition, linkWords(definition))))
+			}
+			if def.ILIDefinition != "" {
+				builderString.WriteString(fmt.Sprintf("   %s\n", paint(theme.Note, "ILI: "+linkWords(def.ILIDefinition))))
+			}
+			if len(def.Frames) != 0 {
+				builderString.WriteString(paint(theme.Heading, "Frames") + ": \n")
+			}
+			for _, frame := range def.Frames {
+				builderString.WriteString(fmt.Sprintf(" > %s\n", paint(theme.Frame, frame)))
+			}
 			if len(def.UseExamples) != 0 {
-                builderString.WriteString("[red::u]Examples[-::-]: \n")
+                builderString.WriteString(paint(theme.Heading, "Examples") + ": \n")
 			}
 			for _, example := range def.UseExamples {
-				builderString.WriteString(fmt.Sprintf(" - [cyan]%s[-]\n", example))
+				if example.Source == ExampleSourceSense {
+					builderString.WriteString(fmt.Sprintf(" * %s\n", paint(theme.SenseExample, linkWords(example.Text))))
+				} else {
+					builderString.WriteString(fmt.Sprintf(" - %s\n", paint(theme.Example, linkWords(example.Text))))
+				}
+			}
+			if len(def.Related) != 0 && !options.showRelated {
+				builderString.WriteString(fmt.Sprintf("%s (%d, %s to expand)\n", paint(theme.Heading, "▸ Related words"), countRelatedWords(def.Related), options.expandKey))
+			} else if len(def.Related) != 0 {
+				builderString.WriteString(paint(theme.Heading, "▾ Related words") + ": \n")
+				for _, related := range def.Related {
+					builderString.WriteString(fmt.Sprintf(" %s: %s\n", relationTypeLabel(related.Relation), paint(theme.Word, linkList(related.Words))))
+				}
 			}
 		}
         builderString.WriteString("\n")
@@ -30,30 +193,889 @@ func generateTextToShow(word *Word) string {
 	return builderString.String()
 }
 
-func initApplication(dict Dictionary) {
+// the roots of VerbRelations for the verbs of the word by synset id, so each
+// verb sense finds its chains
+func verbRelationChains(dict Dictionary, word *Word) map[string]*RelationNode {
+	var chains map[string]*RelationNode = make(map[string]*RelationNode)
+	for _, wordDefinition := range word.WordDefinitions {
+		if wordDefinition.PartOfSpeech != PartOfSpeechVerb {
+			continue
+		}
+		roots, err := dict.VerbRelations(wordDefinition.WrittenForm, DefaultRelationDepth)
+		if err != nil {
+			continue
+		}
+		for _, root := range roots {
+			chains[root.SynsetId] = root
+		}
+	}
+	return chains
+}
+
+func countRelatedWords(related []RelatedWords) int {
+	count := 0
+	for _, r := range related {
+		count += len(r.Words)
+	}
+	return count
+}
+
+// join the values of the tags, the category is used for the tags without value
+func tagsToText(tags []Tag) string {
+	values := make([]string, len(tags))
+	for i, tag := range tags {
+		values[i] = strings.TrimSpace(tag.Value)
+		if values[i] == "" {
+			values[i] = tag.Category
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
+// build the part-whole tree of the word, the wholes and parts are the results
+// of WholesOf and PartsOf and so have one root per sense in the same order
+func generatePartWholeTree(query string, wholes []*RelationNode, parts []*RelationNode) *tview.TreeNode {
+	root := newTreeNode(paint(theme.Headword, query)).SetSelectable(false)
+	for i, whole := range wholes {
+		senseNode := newTreeNode(paint(theme.Definition, fmt.Sprintf("%d: %s", i+1, whole.Definition)))
+		for _, child := range whole.Children {
+			senseNode.AddChild(generateRelationNode(child))
+		}
+		if i < len(parts) {
+			for _, child := range parts[i].Children {
+				senseNode.AddChild(generateRelationNode(child))
+			}
+		}
+		if len(senseNode.GetChildren()) == 0 {
+			senseNode.AddChild(newTreeNode(paint(theme.Note, "no parts or wholes")).SetSelectable(false))
+		}
+		root.AddChild(senseNode)
+	}
+	return root
+}
+
+// the node text shows the relation with the parent node, like "part of → car"
+func generateRelationNode(relationNode *RelationNode) *tview.TreeNode {
+	node := newTreeNode(fmt.Sprintf("%s → %s", paint(theme.Relation, relationTypeLabel(relationNode.Relation)), paint(theme.Word, strings.Join(relationNode.Words, ", "))))
+	node.SetReference(relationNode)
+	for _, child := range relationNode.Children {
+		node.AddChild(generateRelationNode(child))
+	}
+	return node
+}
+
+// tree with the hypernym chains of both words meeting at their lowest common
+// hypernym, followed by the shortest path between the words
+func generateCompareTree(a string, b string, common *CommonHypernym, commonErr error, path []PathStep, pathErr error) *tview.TreeNode {
+	root := newTreeNode(fmt.Sprintf("%s ↔ %s", paint(theme.Headword, a), paint(theme.Headword, b))).SetSelectable(false)
+
+	if commonErr != nil {
+		root.AddChild(newTreeNode(paint(theme.Note, commonErr.Error())).SetSelectable(false))
+	} else {
+		hypernymNode := newTreeNode(fmt.Sprintf("%s → %s", paint(theme.Relation, "lowest common hypernym"), paint(theme.Word, strings.Join(common.Hypernym.Words, ", "))))
+		for _, chain := range [][]PathStep{common.ChainA, common.ChainB} {
+			// the chains go from the word up to the hypernym, draw them from
+			// the hypernym down to the word
+			parent := hypernymNode
+			for i := len(chain) - 2; i >= 0; i-- {
+				node := newTreeNode(paint(theme.Word, strings.Join(chain[i].Words, ", ")))
+				parent.AddChild(node)
+				parent = node
+			}
+		}
+		root.AddChild(hypernymNode)
+	}
+
+	if pathErr != nil {
+		root.AddChild(newTreeNode(paint(theme.Note, pathErr.Error())).SetSelectable(false))
+	} else {
+		pathNode := newTreeNode(fmt.Sprintf("%s (%d steps)", paint(theme.Relation, "shortest path"), len(path)-1))
+		for i, step := range path {
+			if i == 0 {
+				pathNode.AddChild(newTreeNode(paint(theme.Word, strings.Join(step.Words, ", "))))
+			} else {
+				pathNode.AddChild(newTreeNode(fmt.Sprintf("%s → %s", paint(theme.Relation, relationTypeLabel(step.Relation)), paint(theme.Word, strings.Join(step.Words, ", ")))))
+			}
+		}
+		root.AddChild(pathNode)
+	}
+	return root
+}
+
+// hypernym and hyponym relation types shown in the relations tree
+var (
+	treeHypernymTypes map[RelationType]bool = map[RelationType]bool{RelationTypeHypernym: true, RelationTypeInstanceHypernym: true}
+	treeHyponymTypes  map[RelationType]bool = map[RelationType]bool{RelationTypeHyponym: true, RelationTypeInstanceHyponym: true}
+)
+
+// tree with the synonyms, hypernyms, hyponyms and antonyms of the senses, the
+// leaves have the word as reference
+func generateRelationsTree(dict Dictionary, word *Word, sense *senseIndex, verbRelations map[string]*RelationNode) *tview.TreeNode {
+	root := newTreeNode(paint(theme.Headword, "Relations")).SetSelectable(false)
+	addGroup := func(parent *tview.TreeNode, label string, words []string) {
+		if len(words) == 0 {
+			return
+		}
+		group := newTreeNode(fmt.Sprintf("%s (%d)", paint(theme.Relation, label), len(words)))
+		for _, word := range words {
+			group.AddChild(newTreeNode(word).SetReference(word))
+		}
+		parent.AddChild(group)
+	}
+	synsetWords := func(def Def, types map[RelationType]bool) []string {
+		var words []string = make([]string, 0)
+		synset, err := dict.Synset(def.SynsetId)
+		if err != nil {
+			return words
+		}
+		for _, relation := range synset.Relations {
+			if types[relation.Relation] {
+				words = append(words, relation.Words...)
+			}
+		}
+		return words
+	}
+
+	for d, wordDefinition := range word.WordDefinitions {
+		for i, def := range wordDefinition.Definitions {
+			if sense != nil && (sense.definition != d || sense.sense != i) {
+				continue
+			}
+			// a single sense is shown at the root
+			parent := root
+			if sense == nil {
+				parent = newTreeNode(paint(theme.Definition, fmt.Sprintf("%s (%s) %d", wordDefinition.WrittenForm, wordDefinition.PartOfSpeech, i+1)))
+				root.AddChild(parent)
+			}
+			addGroup(parent, "Synonyms", def.Synonyms)
+			addGroup(parent, "Hypernyms", synsetWords(def, treeHypernymTypes))
+			addGroup(parent, "Hyponyms", synsetWords(def, treeHyponymTypes))
+			for _, related := range def.Related {
+				if related.Relation == RelationTypeAntonym {
+					addGroup(parent, "Antonyms", related.Words)
+				}
+			}
+			if chain, ok := verbRelations[def.SynsetId]; ok && len(chain.Children) != 0 {
+				group := newTreeNode(paint(theme.Relation, "Entailment and causation"))
+				for _, child := range chain.Children {
+					group.AddChild(generateRelationNode(child))
+				}
+				parent.AddChild(group)
+			}
+			if len(parent.GetChildren()) == 0 {
+				parent.AddChild(newTreeNode(paint(theme.Note, "no relations")).SetSelectable(false))
+			}
+			// the senses start collapsed when all of them are shown
+			parent.SetExpanded(sense != nil)
+		}
+	}
+	return root
+}
+
+// tree node with the selection style of the theme
+func newTreeNode(text string) *tview.TreeNode {
+	return tview.NewTreeNode(text).SetSelectedTextStyle(selectedStyle(theme))
+}
+
+// center the primitive in a transparent layout of the size, used for the
+// modal inputs
+func centered(p tview.Primitive, width int, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false)
+}
+
+func initApplication(dict Dictionary, config *Config) {
+	theme = config.Theme
+	applyTviewTheme(theme)
 	app := tview.NewApplication().EnableMouse(true)
+	pages := tview.NewPages()
 	flex := tview.NewFlex().SetDirection(tview.FlexRow)
 
-	textView := tview.NewTextView().SetDynamicColors(true)
+	// part-whole explorer, opened with Ctrl-P and closed with Esc or Ctrl-P
+	treeView := tview.NewTreeView()
+	treeView.SetBorder(true).SetTitle("Parts and wholes")
+	treeView.SetSelectedFunc(func(node *tview.TreeNode) {
+		node.SetExpanded(!node.IsExpanded())
+	})
+
+	textView := tview.NewTextView().SetDynamicColors(true).SetRegions(true)
 	textView.SetBorder(true).SetTitle("Definition")
+	// senses of the word, the first item shows all of them
+	senseList := tview.NewList().SetHighlightFullLine(true).SetSelectedStyle(selectedStyle(theme))
+	senseList.SetBorder(true).SetTitle("Senses")
+	var senseIndexes []*senseIndex
+	relationsTree := tview.NewTreeView()
+	relationsTree.SetBorder(true).SetTitle("Relations")
+
+	// last word found and the current display mode, kept to redraw the
+	// definition when the mode is toggled
+	var lastWord *Word
+	var options textOptions = textOptions{
+		verbose:     config.Verbose,
+		showRelated: config.ShowRelated,
+		links:       true,
+		expandKey:   config.Keys["related"].Name,
+	}
+	// number of words that can be followed in the definition
+	var regionCount int
+	// the title shows the display mode and the status of the last action
+	// parts of speech shown, all of them when empty
+	var posFilter []PartOfSpeech
+	setDefinitionTitle := func(status string) {
+		title := "Definition"
+		if !options.verbose {
+			title += " (compact)"
+		}
+		if len(posFilter) != 0 {
+			names := make([]string, len(posFilter))
+			for i, pos := range posFilter {
+				names[i] = pos.String()
+			}
+			title += " " + tview.Escape("["+strings.Join(names, ", ")+"]")
+		}
+		if lastWord != nil {
+			title += " - " + partOfSpeechCounts(lastWord)
+		}
+		if status != "" {
+			title += " - " + status
+		}
+		textView.SetTitle(title)
+	}
+	showWord := func() {
+		if lastWord == nil {
+			return
+		}
+		text := generateTextToShow(lastWord, options)
+		regionCount = strings.Count(text, `[""]`)
+		textView.Highlight()
+		textView.SetText(text)
+		root := generateRelationsTree(dict, lastWord, options.sense, options.verbRelations)
+		relationsTree.SetRoot(root).SetCurrentNode(root)
+	}
+	fillSenseList := func() {
+		senseList.Clear()
+		senseIndexes = []*senseIndex{nil}
+		senseList.AddItem("All senses", "", 0, nil)
+		if lastWord == nil {
+			return
+		}
+		for d, wordDefinition := range lastWord.WordDefinitions {
+			for i, def := range wordDefinition.Definitions {
+				definition := ""
+				if len(def.Definitions) != 0 {
+					definition = def.Definitions[0]
+				}
+				senseIndexes = append(senseIndexes, &senseIndex{definition: d, sense: i})
+				senseList.AddItem(fmt.Sprintf("%d. %s (%s)", i+1, wordDefinition.WrittenForm, wordDefinition.PartOfSpeech), definition, 0, nil)
+			}
+		}
+	}
+	senseList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		if index >= len(senseIndexes) || options.sense == senseIndexes[index] {
+			return
+		}
+		options.sense = senseIndexes[index]
+		textView.ScrollToBeginning()
+		showWord()
+	})
 
 	textArea := tview.NewTextArea().SetLabel("Enter you search: ")
 	textArea.SetBorder(true).SetBorderAttributes(tcell.AttrBold)
-	textArea.SetChangedFunc(func() {
+	// query of the word being shown
+	var shownQuery string
+	showResult := func(query string, word *Word, err error) {
+		shownQuery = query
 		textView.ScrollToBeginning()
-		input := textArea.GetText()
-		word, err := dict.Search(input)
+		options.sense = nil
 		if err != nil {
+			lastWord = nil
+			regionCount = 0
+			textView.Highlight()
 			textView.SetText("Word not found!")
+			relationsTree.SetRoot(nil)
 		} else {
-			textView.SetText(generateTextToShow(word))
+			lastWord = word
+			options.verbRelations = verbRelationChains(dict, word)
+			showWord()
 		}
+		setDefinitionTitle("")
+		fillSenseList()
+	}
+	// the query searched for the text typed, with the filter of the parts of
+	// speech unless the text has its own
+	searchQuery := func(text string) string {
+		if _, _, ok := splitPartOfSpeechFilter(text); ok {
+			return text
+		}
+		return withPartOfSpeechFilter(text, posFilter)
+	}
+	// the words typed are searched by the worker, the other lookups are
+	// synchronous and discard the typed ones still pending, the search of a
+	// stale query stops at the next sense
+	lookupCtx, stopLookups := context.WithCancel(context.Background())
+	defer stopLookups()
+	lookupWorker := newAsyncLookup(lookupCtx, lookupDebounce, dict.SearchContext, func(query string, word *Word, err error) {
+		app.QueueUpdateDraw(func() {
+			// a synchronous lookup may have changed the query meanwhile
+			if query == searchQuery(textArea.GetText()) {
+				showResult(query, word, err)
+			}
+		})
+	})
+	lookUp := func(text string) {
+		lookupWorker.Cancel()
+		query := searchQuery(text)
+		word, err := dict.Search(query)
+		showResult(query, word, err)
+	}
+	textArea.SetChangedFunc(func() {
+		lookupWorker.Request(searchQuery(textArea.GetText()))
+	})
+	// add or remove the part of speech of the filter and search again
+	togglePartOfSpeech := func(pos PartOfSpeech) {
+		filtered := make([]PartOfSpeech, 0, len(posFilter)+1)
+		for _, filter := range posFilter {
+			if filter != pos {
+				filtered = append(filtered, filter)
+			}
+		}
+		if len(filtered) == len(posFilter) {
+			filtered = append(filtered, pos)
+		}
+		posFilter = filtered
+		lookUp(textArea.GetText())
+	}
+
+	// the history is kept only in memory when the file can't be read or written
+	history, err := LoadHistory(historyPath())
+	if err != nil {
+		history = &History{entries: make([]string, 0)}
+	}
+	historyList := tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true).SetSelectedStyle(selectedStyle(theme))
+	historyList.SetBorder(true).SetTitle("History")
+	refreshHistory := func() {
+		historyList.Clear()
+		for _, word := range history.Recent(0) {
+			historyList.AddItem(word, "", 0, nil)
+		}
+	}
+	refreshHistory()
+	// show the word without recording it, used by the navigation
+	showHistoryWord := func(word string) {
+		textArea.SetText(word, true)
+		lookUp(word)
+	}
+	recordLookUp := func() {
+		// the word typed may be still waiting for the worker
+		if shownQuery != searchQuery(textArea.GetText()) {
+			lookUp(textArea.GetText())
+		}
+		if lastWord == nil {
+			return
+		}
+		history.Add(textArea.GetText())
+		refreshHistory()
+	}
+	// search a word of the definition, as written or by its lemma, keeping
+	// the current word in the history so Alt-Left comes back to it
+	followWord := func(text string) {
+		query := text
+		// checked with the filter of the parts of speech, as lookUp searches it
+		if _, err := dict.Search(searchQuery(query)); err != nil {
+			lemma, ok := dict.Lemmatize(text)
+			if !ok {
+				setDefinitionTitle(fmt.Sprintf("%s not found", text))
+				return
+			}
+			query = lemma
+		}
+		recordLookUp()
+		showHistoryWord(query)
+		recordLookUp()
+		app.SetFocus(textArea)
+	}
+	followLink := func(regionId string) {
+		followWord(strings.TrimSpace(textView.GetRegionText(regionId)))
+	}
+	relationsTree.SetSelectedFunc(func(node *tview.TreeNode) {
+		if word, ok := node.GetReference().(string); ok {
+			followWord(word)
+		} else {
+			node.SetExpanded(!node.IsExpanded())
+		}
+	})
+	// move the highlight to the next word that can be followed, or to the
+	// previous one when step is negative
+	var cycling bool
+	cycleLink := func(step int) {
+		if regionCount == 0 {
+			return
+		}
+		next := 0
+		if step < 0 {
+			next = regionCount - 1
+		}
+		if highlights := textView.GetHighlights(); len(highlights) != 0 {
+			var current int
+			fmt.Sscanf(highlights[0], "w%d", &current)
+			next = (current + step + regionCount) % regionCount
+		}
+		cycling = true
+		textView.Highlight(fmt.Sprintf("w%d", next)).ScrollToHighlight()
+		cycling = false
+	}
+	// a click on a word follows it
+	textView.SetHighlightedFunc(func(added, removed, remaining []string) {
+		if len(added) != 0 && !cycling {
+			followLink(added[0])
+		}
+	})
+	// Enter follows the highlighted word or records the word being shown,
+	// instead of breaking the line
+	textArea.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEnter {
+			if highlights := textView.GetHighlights(); len(highlights) != 0 {
+				followLink(highlights[0])
+			} else {
+				recordLookUp()
+			}
+			return nil
+		}
+		return event
+	})
+	// in narrow terminals the history is shown in a page of its own
+	closeHistory := func() {
+		if name, _ := pages.GetFrontPage(); name == "history" {
+			pages.HidePage("history")
+		}
+		app.SetFocus(textArea)
+	}
+	historyList.SetSelectedFunc(func(index int, word string, secondaryText string, shortcut rune) {
+		showHistoryWord(word)
+		recordLookUp()
+		closeHistory()
+	})
+	historyList.SetDoneFunc(closeHistory)
+	// the recent words can be followed like the words of a definition
+	if recent := history.Recent(10); len(recent) != 0 {
+		builder := &strings.Builder{}
+		builder.WriteString(paint(theme.Definition, "Recent words:") + "\n")
+		for i, word := range recent {
+			builder.WriteString(fmt.Sprintf(`  ["w%d"]%s[""]`+"\n", i, word))
+		}
+		regionCount = len(recent)
+		textView.SetText(builder.String())
+	}
+
+	showPartWholeTree := func() {
+		query := textArea.GetText()
+		wholes, err := dict.WholesOf(query, DefaultRelationDepth)
+		if err != nil {
+			return
+		}
+		parts, err := dict.PartsOf(query, DefaultRelationDepth)
+		if err != nil {
+			return
+		}
+		root := generatePartWholeTree(query, wholes, parts)
+		treeView.SetTitle("Parts and wholes")
+		treeView.SetRoot(root).SetCurrentNode(root)
+		if len(root.GetChildren()) != 0 {
+			treeView.SetCurrentNode(root.GetChildren()[0])
+		}
+		pages.SwitchToPage("tree")
+	}
+
+	// compare view, Ctrl-O asks for the word to compare with the current one
+	compareInput := tview.NewInputField().SetLabel("Compare with: ")
+	compareInput.SetBorder(true).SetTitle("Compare")
+	compareInput.SetDoneFunc(func(key tcell.Key) {
+		pages.HidePage("compare")
+		if key != tcell.KeyEnter {
+			return
+		}
+		a, b := textArea.GetText(), compareInput.GetText()
+		common, commonErr := dict.LowestCommonHypernym(a, b)
+		path, pathErr := dict.ShortestPath(a, b)
+		root := generateCompareTree(a, b, common, commonErr, path, pathErr)
+		treeView.SetTitle("Compare")
+		treeView.SetRoot(root).SetCurrentNode(root)
+		if len(root.GetChildren()) != 0 {
+			treeView.SetCurrentNode(root.GetChildren()[0])
+		}
+		pages.SwitchToPage("tree")
+	})
+	compareModal := centered(compareInput, 60, 3)
+
+	// the lists are kept only in memory when the file can't be read
+	wordLists, err := LoadWordLists(wordListsPath())
+	if err != nil {
+		wordLists = &WordLists{Lists: make([]*WordList, 0)}
+	}
+	listNames := tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true).SetSelectedStyle(selectedStyle(theme))
+	listNames.SetBorder(true).SetTitle("Lists (r reviews)")
+	listWords := tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true).SetSelectedStyle(selectedStyle(theme))
+	listWords.SetBorder(true).SetTitle("Words (Delete removes)")
+	showListWords := func(name string) {
+		listWords.Clear()
+		list, err := wordLists.Find(name)
+		if err != nil {
+			return
+		}
+		listWords.SetTitle(fmt.Sprintf("%s (Delete removes)", name))
+		for _, word := range list.Words {
+			listWords.AddItem(word, "", 0, nil)
+		}
+	}
+	refreshLists := func() {
+		current := listNames.GetCurrentItem()
+		listNames.Clear()
+		for _, list := range wordLists.Lists {
+			listNames.AddItem(list.Name, "", 0, nil)
+		}
+		listNames.SetCurrentItem(current)
+		if len(wordLists.Lists) != 0 {
+			showListWords(wordLists.Lists[listNames.GetCurrentItem()].Name)
+		} else {
+			listWords.Clear()
+		}
+	}
+	listNames.SetChangedFunc(func(index int, name string, secondaryText string, shortcut rune) {
+		showListWords(name)
+	})
+	listNames.SetSelectedFunc(func(index int, name string, secondaryText string, shortcut rune) {
+		app.SetFocus(listWords)
+	})
+	listWords.SetSelectedFunc(func(index int, word string, secondaryText string, shortcut rune) {
+		pages.SwitchToPage("main")
+		app.SetFocus(textArea)
+		showHistoryWord(word)
+		recordLookUp()
+	})
+	listWords.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyDelete || listWords.GetItemCount() == 0 || len(wordLists.Lists) == 0 {
+			return event
+		}
+		word, _ := listWords.GetItemText(listWords.GetCurrentItem())
+		wordLists.Remove(wordLists.Lists[listNames.GetCurrentItem()].Name, word)
+		current := listWords.GetCurrentItem()
+		refreshLists()
+		listWords.SetCurrentItem(current)
+		return nil
+	})
+	// flashcard review of a list, started with r in the lists
+	reviewState, err := LoadReviewState(reviewStatePath())
+	if err != nil {
+		reviewState = &ReviewState{Cards: make(map[string]*ReviewCard)}
+	}
+	var session *ReviewSession
+	var revealed bool
+	reviewView := tview.NewTextView().SetDynamicColors(true).SetWordWrap(true)
+	reviewView.SetBorder(true)
+	showCard := func() {
+		reviewView.ScrollToBeginning()
+		reviewView.SetTitle(fmt.Sprintf("Review (%d left)", session.Remaining()))
+		word, ok := session.Current()
+		if !ok && session.Reviewed == 0 {
+			reviewView.SetText(fmt.Sprintf("\n  %s\n\n  %s", paint(theme.Success, "No words to review now!"), paint(theme.Note, "Esc goes back to the lists")))
+			return
+		} else if !ok {
+			reviewView.SetText(fmt.Sprintf("\n  %s %d words reviewed.\n\n  %s", paint(theme.Success, "Review finished!"), session.Reviewed, paint(theme.Note, "Esc goes back to the lists")))
+			return
+		}
+		text := fmt.Sprintf("\n  %s\n\n", paint(theme.Headword, word))
+		if !revealed {
+			text += "  " + paint(theme.Note, "Space shows the definitions, Esc ends the review")
+		} else {
+			definition, err := dict.Search(word)
+			if err != nil {
+				text += "Word not found!\n"
+			} else {
+				text += generateTextToShow(definition, textOptions{verbose: true})
+			}
+			text += paint(theme.Definition, "How well did you remember it?") + " 0 blackout, 1 wrong, 2 wrong but familiar, 3 hard, 4 good, 5 perfect"
+		}
+		reviewView.SetText(text)
+	}
+	startReview := func(name string) {
+		list, err := wordLists.Find(name)
+		if err != nil {
+			return
+		}
+		session = NewReviewSession(reviewState, list.Words, time.Now())
+		revealed = false
+		showCard()
+		pages.SwitchToPage("review")
+	}
+
+	listsBrowser := tview.NewFlex().
+		AddItem(listNames, 0, 1, true).
+		AddItem(listWords, 0, 2, false)
+
+	// Ctrl-S asks for the list where the word being shown is added, the last
+	// list used is proposed
+	listInput := tview.NewInputField().SetLabel("Add to list: ").SetText(defaultWordList)
+	listInput.SetBorder(true).SetTitle("Save word")
+	listInput.SetDoneFunc(func(key tcell.Key) {
+		pages.HidePage("save")
+		if key != tcell.KeyEnter || lastWord == nil || len(lastWord.WordDefinitions) == 0 {
+			return
+		}
+		// the lemma is saved, so "mice" is saved as "mouse"
+		word := lastWord.WordDefinitions[0].WrittenForm
+		if wordLists.Add(listInput.GetText(), word) == nil {
+			setDefinitionTitle(fmt.Sprintf("%s saved in %s", word, listInput.GetText()))
+			refreshLists()
+		}
+	})
+	saveModal := centered(listInput, 60, 3)
+
+	// the definition takes the whole width in narrow terminals, the sense
+	// list and the relations tree appear from mediumLayoutWidth and the
+	// history from wideLayoutWidth, below it the history opens as a page
+	body := tview.NewFlex()
+	layoutWidth := -1
+	// the panes hidden by the layout can't have the focus
+	visible := func(pane tview.Primitive) bool {
+		switch pane {
+		case senseList, relationsTree:
+			return layoutWidth >= mediumLayoutWidth
+		case historyList:
+			return layoutWidth >= wideLayoutWidth
+		}
+		return true
+	}
+	// show the panes that fit in the width of the terminal, it's called while
+	// drawing so the focus is moved in a queued update, SetFocus would wait
+	// for the lock held by the draw
+	arrangeBody := func(width int) {
+		switch {
+		case width >= wideLayoutWidth:
+			width = wideLayoutWidth
+		case width >= mediumLayoutWidth:
+			width = mediumLayoutWidth
+		default:
+			width = 0
+		}
+		if width == layoutWidth {
+			return
+		}
+		layoutWidth = width
+		body.Clear()
+		if layoutWidth >= mediumLayoutWidth {
+			body.AddItem(senseList, 30, 0, false)
+		}
+		body.AddItem(textView, 0, 1, false)
+		if layoutWidth >= mediumLayoutWidth {
+			body.AddItem(relationsTree, 30, 0, false)
+		}
+		if layoutWidth >= wideLayoutWidth {
+			body.AddItem(historyList, 24, 0, false)
+		}
+		go app.QueueUpdateDraw(func() {
+			// the history page isn't needed when the pane is shown
+			if name, _ := pages.GetFrontPage(); name == "history" {
+				if visible(historyList) {
+					pages.HidePage("history")
+					app.SetFocus(historyList)
+				}
+				return
+			}
+			// the focus can't stay in a hidden pane
+			for _, pane := range []tview.Primitive{senseList, relationsTree, historyList} {
+				if pane.HasFocus() && !visible(pane) {
+					app.SetFocus(textArea)
+				}
+			}
+		})
+	}
+	arrangeBody(0)
+
+	// move the focus to the next visible pane
+	cycleFocus := func() {
+		panes := []tview.Primitive{textArea, senseList, textView, relationsTree, historyList}
+		current := 0
+		for i, pane := range panes {
+			if pane.HasFocus() {
+				current = i
+			}
+		}
+		for i := 1; i < len(panes); i++ {
+			if next := panes[(current+i)%len(panes)]; visible(next) {
+				app.SetFocus(next)
+				return
+			}
+		}
+	}
+
+	// with the default keys of the config, Ctrl-T switches between the
+	// compact and the verbose view, Ctrl-R expands or collapses the related
+	// words, Ctrl-P opens the part-whole explorer, Ctrl-O the compare view,
+	// Ctrl-Y moves the focus to the history and Alt-Left/Alt-Right go back and
+	// forward in it, Ctrl-S saves the word in a list and Ctrl-L opens the
+	// lists, Tab and Shift-Tab move through the words of the definition that
+	// Enter follows, Ctrl-N moves the focus to the next pane and Alt-N, Alt-V,
+	// Alt-A and Alt-R show only the nouns, verbs, adjectives or adverbs
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch name, _ := pages.GetFrontPage(); name {
+		case "tree":
+			if event.Key() == tcell.KeyEscape || config.Keys.Is("part_whole", event) {
+				pages.SwitchToPage("main")
+				return nil
+			}
+			return event
+		case "lists":
+			if event.Key() == tcell.KeyEscape && listWords.HasFocus() {
+				app.SetFocus(listNames)
+				return nil
+			}
+			if event.Key() == tcell.KeyEscape || config.Keys.Is("lists", event) {
+				pages.SwitchToPage("main")
+				return nil
+			}
+			if event.Rune() == 'r' && listNames.HasFocus() && len(wordLists.Lists) != 0 {
+				startReview(wordLists.Lists[listNames.GetCurrentItem()].Name)
+				return nil
+			}
+			return event
+		case "review":
+			switch {
+			case event.Key() == tcell.KeyEscape:
+				pages.SwitchToPage("lists")
+			case !revealed && (event.Key() == tcell.KeyEnter || event.Rune() == ' '):
+				if _, ok := session.Current(); ok {
+					revealed = true
+					showCard()
+				}
+			case revealed && event.Rune() >= '0' && event.Rune() <= '5':
+				err := session.Grade(int(event.Rune()-'0'), time.Now())
+				revealed = false
+				showCard()
+				if err != nil {
+					reviewView.SetTitle(fmt.Sprintf("Review (%d left, not saved: %s)", session.Remaining(), err))
+				}
+			default:
+				return event
+			}
+			return nil
+		case "history":
+			if config.Keys.Is("history", event) {
+				closeHistory()
+				return nil
+			}
+			return event
+		case "compare", "save":
+			return event
+		}
+		keys := config.Keys
+		switch {
+		case keys.Is("back", event):
+			if word, ok := history.Back(); ok {
+				showHistoryWord(word)
+			}
+			return nil
+		case keys.Is("forward", event):
+			if word, ok := history.Forward(); ok {
+				showHistoryWord(word)
+			}
+			return nil
+		case keys.Is("next_link", event):
+			cycleLink(1)
+			return nil
+		case keys.Is("prev_link", event):
+			cycleLink(-1)
+			return nil
+		case event.Key() == tcell.KeyEscape:
+			if len(textView.GetHighlights()) != 0 {
+				textView.Highlight()
+				return nil
+			}
+		case event.Key() == tcell.KeyEnter:
+			if highlights := textView.GetHighlights(); textView.HasFocus() && len(highlights) != 0 {
+				followLink(highlights[0])
+				return nil
+			}
+		case keys.Is("next_pane", event):
+			cycleFocus()
+			return nil
+		case keys.Is("history", event):
+			if !visible(historyList) {
+				pages.ShowPage("history")
+				return nil
+			}
+			if historyList.HasFocus() {
+				app.SetFocus(textArea)
+			} else {
+				app.SetFocus(historyList)
+			}
+			return nil
+		case keys.Is("save", event):
+			if lastWord != nil {
+				pages.ShowPage("save")
+			}
+			return nil
+		case keys.Is("lists", event):
+			refreshLists()
+			pages.SwitchToPage("lists")
+			return nil
+		case keys.Is("part_whole", event):
+			showPartWholeTree()
+			return nil
+		case keys.Is("compare", event):
+			if textArea.GetText() == "" {
+				return nil
+			}
+			compareInput.SetText("")
+			pages.ShowPage("compare")
+			return nil
+		case keys.Is("verbose", event):
+			options.verbose = !options.verbose
+			setDefinitionTitle("")
+			showWord()
+			return nil
+		case keys.Is("related", event):
+			options.showRelated = !options.showRelated
+			showWord()
+			return nil
+		case keys.Is("filter_noun", event):
+			togglePartOfSpeech(PartOfSpeechNoun)
+			return nil
+		case keys.Is("filter_verb", event):
+			togglePartOfSpeech(PartOfSpeechVerb)
+			return nil
+		case keys.Is("filter_adjective", event):
+			togglePartOfSpeech(PartOfSpeechAdjective)
+			return nil
+		case keys.Is("filter_adverb", event):
+			togglePartOfSpeech(PartOfSpeechAdverb)
+			return nil
+		}
+		return event
 	})
 
-	flex.AddItem(textView, 0, 9, false)
+	// the panes are arranged again when the terminal crosses a layout width
+	app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		width, _ := screen.Size()
+		arrangeBody(width)
+		return false
+	})
+	flex.AddItem(body, 0, 9, false)
 	flex.AddItem(textArea, 0, 1, true)
 
-	app.SetRoot(flex, true)
+	pages.AddPage("main", flex, true, true)
+	pages.AddPage("tree", treeView, true, false)
+	pages.AddPage("compare", compareModal, true, false)
+	pages.AddPage("lists", listsBrowser, true, false)
+	pages.AddPage("save", saveModal, true, false)
+	pages.AddPage("review", reviewView, true, false)
+	pages.AddPage("history", centered(historyList, 40, 20), true, false)
+
+	app.SetRoot(pages, true)
 
 	if err := app.Run(); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+var ErrListNotFound = errors.New("List not found!")
+var ErrWordNotInList = errors.New("Word not in the list!")
+
+// list used when the user doesn't choose one
+const defaultWordList = "favorites"
+
+type WordList struct {
+	Name  string   `json:"name"`
+	Words []string `json:"words"`
+}
+
+// named lists of words saved by the user, stored as a JSON file
+type WordLists struct {
+	path  string
+	Lists []*WordList `json:"lists"`
+}
+
+func wordListsPath() string {
+	return filepath.Join(dataDir(), "lists.json")
+}
+
+// load the lists from the file, a missing file gives no lists
+func LoadWordLists(path string) (*WordLists, error) {
+	wl := &WordLists{path: path, Lists: make([]*WordList, 0)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return wl, nil
+	}
+	if err != nil {
+		return wl, err
+	}
+	if err := json.Unmarshal(data, wl); err != nil {
+		return wl, err
+	}
+	return wl, nil
+}
+
+func (wl *WordLists) Save() error {
+	if wl.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(wl, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(wl.path, append(data, '\n'))
+}
+
+func (wl *WordLists) Find(name string) (*WordList, error) {
+	for _, list := range wl.Lists {
+		if list.Name == name {
+			return list, nil
+		}
+	}
+	return nil, ErrListNotFound
+}
+
+func (wl *WordLists) Names() []string {
+	var names []string = make([]string, len(wl.Lists))
+	for i, list := range wl.Lists {
+		names[i] = list.Name
+	}
+	return names
+}
+
+// add the word at the end of the list, creating the list if it doesn't exist,
+// a word already in the list isn't added again
+func (wl *WordLists) Add(name string, word string) error {
+	name, word = strings.TrimSpace(name), strings.TrimSpace(word)
+	if name == "" || word == "" {
+		return errors.New("Empty list name or word!")
+	}
+	list, err := wl.Find(name)
+	if err != nil {
+		list = &WordList{Name: name, Words: make([]string, 0)}
+		wl.Lists = append(wl.Lists, list)
+	}
+	for _, listWord := range list.Words {
+		if listWord == word {
+			return nil
+		}
+	}
+	list.Words = append(list.Words, word)
+	return wl.Save()
+}
+
+func (wl *WordLists) Remove(name string, word string) error {
+	list, err := wl.Find(name)
+	if err != nil {
+		return err
+	}
+	for i, listWord := range list.Words {
+		if listWord == word {
+			list.Words = append(list.Words[:i], list.Words[i+1:]...)
+			return wl.Save()
+		}
+	}
+	return ErrWordNotInList
+}
+
+func (wl *WordLists) Delete(name string) error {
+	for i, list := range wl.Lists {
+		if list.Name == name {
+			wl.Lists = append(wl.Lists[:i], wl.Lists[i+1:]...)
+			return wl.Save()
+		}
+	}
+	return ErrListNotFound
+}
+
+// write the words of the list with their definitions in the format: the
+// glossary ones (json, csv and markdown) or anki, the words no longer found
+// are skipped
+func exportWordList(w io.Writer, dict *OpenEnglishDictionary, list *WordList, format string) error {
+	entries := lookupBatch(dict, list.Words, runtime.NumCPU())
+	if format == "anki" {
+		return writeAnkiNotes(w, entries)
+	}
+	return writeGlossary(w, entries, format)
+}
+
+// tab separated notes for the Anki text import, the word on the front and
+// the senses as HTML on the back
+func writeAnkiNotes(w io.Writer, entries []BatchEntry) error {
+	// fields can't have tabs or line breaks
+	clean := func(text string) string {
+		return html.EscapeString(strings.Join(strings.Fields(text), " "))
+	}
+	if _, err := io.WriteString(w, "#separator:tab\n#html:true\n"); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Err != nil {
+			continue
+		}
+		back := &strings.Builder{}
+		for _, wordDefinition := range entry.Word.WordDefinitions {
+			back.WriteString(fmt.Sprintf("<b>%s</b> <i>(%s)</i><ol>", clean(wordDefinition.WrittenForm), clean(wordDefinition.PartOfSpeech.String())))
+			for _, def := range wordDefinition.Definitions {
+				back.WriteString(fmt.Sprintf("<li>%s", clean(strings.Join(def.Definitions, "; "))))
+				for _, example := range def.UseExamples {
+					back.WriteString(fmt.Sprintf("<br><i>\"%s\"</i>", clean(example.Text)))
+				}
+				back.WriteString("</li>")
+			}
+			back.WriteString("</ol>")
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", clean(entry.Term), back.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// weight of the context words found in the gloss and examples of the sense
+// itself and in the ones of the synsets related to it (extended Lesk)
+const (
+	leskOwnWeight     = 2
+	leskRelatedWeight = 1
+)
+
+// sense of a lexical entry with its score in a context
+type scoredSense struct {
+	lexicalEntry *LexicalEntry
+	sense        *Sense
+	score        int
+}
+
+// search the word with the senses ordered by their relevance in the context,
+// the lexical entries are ordered by their best sense
+func (oe *OpenEnglishDictionary) SearchInContext(query string, context string) (*Word, error) {
+	scored, err := oe.scoreSenses(query, context)
+	if err != nil {
+		return nil, err
+	}
+	wordToReturn := NewWord()
+	var positions map[*LexicalEntry]int = make(map[*LexicalEntry]int)
+	for _, s := range scored {
+		position, ok := positions[s.lexicalEntry]
+		if !ok {
+			position = len(wordToReturn.WordDefinitions)
+			positions[s.lexicalEntry] = position
+			wordToReturn.WordDefinitions = append(wordToReturn.WordDefinitions, oe.newWordDefinition(s.lexicalEntry, make([]Def, 0)))
+		}
+		wordDefinition := &wordToReturn.WordDefinitions[position]
+		wordDefinition.Definitions = append(wordDefinition.Definitions, oe.newDef(s.lexicalEntry, s.sense))
+	}
+	return wordToReturn, nil
+}
+
+// rank the senses of the word by the overlap of the context with their
+// glosses and examples and with the glosses of the related synsets, the
+// senses with the same score keep the dictionary order
+func (oe *OpenEnglishDictionary) scoreSenses(query string, context string) ([]scoredSense, error) {
+	finded, err := oe.findLexicalEntries(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// the word itself doesn't help to choose between its senses
+	var contextWords map[string]bool = oe.contentWords(context)
+	for _, lexicalEntry := range finded {
+		delete(contextWords, lexicalEntry.Lemma.WrittenForm)
+	}
+
+	var scored []scoredSense = make([]scoredSense, 0)
+	for _, lexicalEntry := range finded {
+		for _, sense := range lexicalEntry.Senses {
+			scored = append(scored, scoredSense{
+				lexicalEntry: lexicalEntry,
+				sense:        sense,
+				score:        oe.leskScore(sense, contextWords),
+			})
+		}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+	return scored, nil
+}
+
+func (oe *OpenEnglishDictionary) leskScore(sense *Sense, contextWords map[string]bool) int {
+	if sense.Synset == nil || len(contextWords) == 0 {
+		return 0
+	}
+	ownText := &strings.Builder{}
+	for _, definition := range sense.Synset.Definitions {
+		ownText.WriteString(string(definition) + " ")
+	}
+	for _, example := range sense.Examples {
+		ownText.WriteString(string(example) + " ")
+	}
+	for _, example := range sense.Synset.Examples {
+		ownText.WriteString(string(example) + " ")
+	}
+	for _, word := range oe.synsetWords(sense.Synset) {
+		ownText.WriteString(word + " ")
+	}
+
+	relatedText := &strings.Builder{}
+	for _, synsetRelation := range sense.Synset.SynsetRelations {
+		if synsetRelation.Target == nil {
+			continue
+		}
+		for _, definition := range synsetRelation.Target.Definitions {
+			relatedText.WriteString(string(definition) + " ")
+		}
+		for _, word := range oe.synsetWords(synsetRelation.Target) {
+			relatedText.WriteString(word + " ")
+		}
+	}
+	for _, senseRelation := range sense.SenseRelations {
+		if lexicalEntry, ok := oe.senseToLexicalEntry[senseRelation.Target]; ok {
+			relatedText.WriteString(lexicalEntry.Lemma.WrittenForm + " ")
+		}
+	}
+
+	score := 0
+	ownWords := oe.contentWords(ownText.String())
+	for word := range oe.contentWords(relatedText.String()) {
+		if contextWords[word] && !ownWords[word] {
+			score += leskRelatedWeight
+		}
+	}
+	for word := range ownWords {
+		if contextWords[word] {
+			score += leskOwnWeight
+		}
+	}
+	return score
+}
+
+// return the lemmas of the content words of the text, the words not in the
+// dictionary are kept in lower case
+func (oe *OpenEnglishDictionary) contentWords(text string) map[string]bool {
+	var words map[string]bool = make(map[string]bool)
+	for _, token := range tokenize(text) {
+		base := strings.TrimSuffix(token, "'s")
+		if !isWordToken(token) || stopWords[strings.ToLower(base)] {
+			continue
+		}
+		if lemma, ok := oe.Lemmatize(base); ok {
+			words[lemma] = true
+		} else {
+			words[strings.ToLower(base)] = true
+		}
+	}
+	return words
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// dictionary with the noun "bank" in two senses, each with a hypernym
+//
+//	test-river-bank-n  "sloping land beside a river"  -> "an incline of the ground"
+//	test-money-bank-n  "an institution that keeps money"  -> "an organization founded for a purpose"
+func newBankDictionary() *OpenEnglishDictionary {
+	lexicon := newLexicon()
+	lexicalEntry := NewLexicalEntry()
+	lexicalEntry.Id = "test-bank-n"
+	lexicalEntry.Lemma = NewLemma()
+	lexicalEntry.Lemma.WrittenForm = "bank"
+	lexicalEntry.Lemma.PartOfSpeech = PartOfSpeechNoun
+	addSense := func(synsetId string, gloss string, hypernymGloss string) {
+		hypernym := NewSynset()
+		hypernym.Id = synsetId + "-hypernym"
+		hypernym.PartOfSpeech = PartOfSpeechNoun
+		hypernym.Definitions = append(hypernym.Definitions, Definition(hypernymGloss))
+		synset := NewSynset()
+		synset.Id = synsetId
+		synset.PartOfSpeech = PartOfSpeechNoun
+		synset.Definitions = append(synset.Definitions, Definition(gloss))
+		synset.SynsetRelations = append(synset.SynsetRelations, NewSynsetRelation(hypernym, "hypernym"))
+		sense := NewSense()
+		sense.Id = synsetId + "-sense"
+		sense.Synset = synset
+		lexicalEntry.Senses = append(lexicalEntry.Senses, sense)
+		lexicon.Synsets = append(lexicon.Synsets, synset, hypernym)
+	}
+	addSense("test-river-bank-n", "sloping land beside a river", "an incline of the ground")
+	addSense("test-money-bank-n", "an institution that keeps money", "an organization founded for a purpose")
+	lexicon.LexicalEntrys = append(lexicon.LexicalEntrys, lexicalEntry)
+	lexicalResource := newLexicalResource()
+	lexicalResource.Lexicons = append(lexicalResource.Lexicons, lexicon)
+	return NewOpenEnglishDictionary(lexicalResource)
+}
+
+func TestScoreSenses(t *testing.T) {
+	dict := newBankDictionary()
+	tests := []struct {
+		name    string
+		context string
+		// synset of each sense in the ranked order and its score
+		synsets []string
+		scores  []int
+	}{
+		{"own gloss", "the money in the bank", []string{"test-money-bank-n", "test-river-bank-n"}, []int{2, 0}},
+		{"own gloss of the second sense", "a walk by the river bank", []string{"test-river-bank-n", "test-money-bank-n"}, []int{2, 0}},
+		{"related gloss", "a bank with an organization", []string{"test-money-bank-n", "test-river-bank-n"}, []int{1, 0}},
+		{"own over related", "an incline and money", []string{"test-money-bank-n", "test-river-bank-n"}, []int{2, 1}},
+		{"tie keeps the dictionary order", "money on the ground incline", []string{"test-river-bank-n", "test-money-bank-n"}, []int{2, 2}},
+		{"no overlap", "a sunny day", []string{"test-river-bank-n", "test-money-bank-n"}, []int{0, 0}},
+	}
+	for _, test := range tests {
+		scored, err := dict.scoreSenses("bank", test.context)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		var synsets []string = make([]string, len(scored))
+		var scores []int = make([]int, len(scored))
+		for i, s := range scored {
+			synsets[i] = s.sense.Synset.Id
+			scores[i] = s.score
+		}
+		if !reflect.DeepEqual(synsets, test.synsets) {
+			t.Errorf("%s: got senses %v, want %v", test.name, synsets, test.synsets)
+		}
+		if !reflect.DeepEqual(scores, test.scores) {
+			t.Errorf("%s: got scores %v, want %v", test.name, scores, test.scores)
+		}
+	}
+}
+
+func TestSearchInContext(t *testing.T) {
+	dict := newBankDictionary()
+	word, err := dict.SearchInContext("bank", "the money in the bank")
+	if err != nil {
+		t.Fatal(err)
+	}
+	definitions := word.WordDefinitions[0].Definitions
+	if len(definitions) != 2 {
+		t.Fatalf("got %d senses, want 2", len(definitions))
+	}
+	if definitions[0].SynsetId != "test-money-bank-n" {
+		t.Errorf("got the first sense %q, want test-money-bank-n", definitions[0].SynsetId)
+	}
+}
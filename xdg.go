@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// name of the directory of the application inside the XDG base directories
+const appDirName = "word-def"
+
+// directory of the application inside the XDG base directory of the
+// environment variable, falling back to the default path inside the home
+// directory when the variable isn't set or isn't absolute
+func xdgDir(env string, fallback ...string) string {
+	dir := os.Getenv(env)
+	if dir == "" || !filepath.IsAbs(dir) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			home = "."
+		}
+		dir = filepath.Join(append([]string{home}, fallback...)...)
+	}
+	return filepath.Join(dir, appDirName)
+}
+
+// the state directory keeps data that survives restarts but isn't important,
+// like the history
+func stateDir() string {
+	return xdgDir("XDG_STATE_HOME", ".local", "state")
+}
+
+// write the data to a temporary file and rename it over the path, so a crash
+// never leaves a truncated file, the directory is created if missing
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// the data directory keeps the user files, like the word lists
+func dataDir() string {
+	return xdgDir("XDG_DATA_HOME", ".local", "share")
+}